@@ -0,0 +1,238 @@
+package analytics
+
+import (
+	"bytes"
+	"encoding/binary"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const hourLayout = "2006-01-02T15"
+
+var bucketHourly = []byte("hourly")
+
+// View: 페이지 조회 한 번
+type View struct {
+	Time time.Time
+	Site string
+	// Route: 페이지 구분. ex) index, category:서울:강남구:쩜오, store:서울:강남구:역삼동:쩜오:에이원
+	Route string
+	// Referrer: 이전 페이지의 Host. 같은 사이트면 internal, 없으면 빈 값
+	Referrer string
+	// Agent: Human 또는 크롤러 이름
+	Agent string
+}
+
+// Bucket: 한 시간 동안 같은 사이트, 페이지, 방문자 종류, Referrer로 들어온 조회수
+type Bucket struct {
+	Hour     time.Time `json:"hour"`
+	Site     string    `json:"site"`
+	Route    string    `json:"route"`
+	Agent    string    `json:"agent"`
+	Referrer string    `json:"referrer,omitempty"`
+	Views    uint64    `json:"views"`
+}
+
+// key: 시간이 맨 앞이라 기간 조회는 Seek 한번으로 가능
+func (b *Bucket) key() []byte {
+	return []byte(strings.Join([]string{b.Hour.Format(hourLayout), b.Site, b.Route, b.Agent, b.Referrer}, "\x00"))
+}
+
+func parseKey(k []byte) (*Bucket, bool) {
+	parts := strings.Split(string(k), "\x00")
+	if len(parts) != 5 {
+		return nil, false
+	}
+	hour, err := time.ParseInLocation(hourLayout, parts[0], time.Local)
+	if err != nil {
+		return nil, false
+	}
+	return &Bucket{Hour: hour, Site: parts[1], Route: parts[2], Agent: parts[3], Referrer: parts[4]}, true
+}
+
+// Recorder: 조회수를 메모리에 모았다가 Flush 할 때 한번에 DB에 더함
+type Recorder struct {
+	db      *bolt.DB
+	mu      sync.Mutex
+	pending map[string]uint64
+}
+
+func Open(path string) (*Recorder, error) {
+	db, err := bolt.Open(path, 0o644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketHourly)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Recorder{db: db, pending: map[string]uint64{}}, nil
+}
+
+func (r *Recorder) Record(v *View) {
+	b := &Bucket{
+		Hour:     v.Time.Truncate(time.Hour),
+		Site:     v.Site,
+		Route:    v.Route,
+		Agent:    v.Agent,
+		Referrer: v.Referrer,
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending[string(b.key())]++
+}
+
+func (r *Recorder) Flush() error {
+	r.mu.Lock()
+	pending := r.pending
+	r.pending = map[string]uint64{}
+	r.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket(bucketHourly)
+		for k, n := range pending {
+			if v := bk.Get([]byte(k)); len(v) == 8 {
+				n += binary.BigEndian.Uint64(v)
+			}
+			v := make([]byte, 8)
+			binary.BigEndian.PutUint64(v, n)
+			if err := bk.Put([]byte(k), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		// 다음 Flush에서 다시 시도
+		r.mu.Lock()
+		for k, n := range pending {
+			r.pending[k] += n
+		}
+		r.mu.Unlock()
+	}
+	return err
+}
+
+// Run: interval마다 Flush. 서버 시작시 goroutine으로 실행
+func (r *Recorder) Run(interval time.Duration) {
+	for range time.Tick(interval) {
+		if err := r.Flush(); err != nil {
+			log.Printf("analytics: flush: %s", err)
+		}
+	}
+}
+
+func (r *Recorder) Close() error {
+	err := r.Flush()
+	if cerr := r.db.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Buckets: site의 [from, to) 기간 시간별 조회수. 아직 Flush 하지 않은 조회수도 포함
+func (r *Recorder) Buckets(site string, from, to time.Time) ([]*Bucket, error) {
+	if err := r.Flush(); err != nil {
+		return nil, err
+	}
+	list := []*Bucket{}
+	start := []byte(from.Truncate(time.Hour).Format(hourLayout))
+	// to가 정각이 아니면 그 시간도 포함
+	end := []byte(to.Add(time.Hour - time.Nanosecond).Truncate(time.Hour).Format(hourLayout))
+	err := r.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketHourly).Cursor()
+		for k, v := c.Seek(start); k != nil && bytes.Compare(k, end) < 0; k, v = c.Next() {
+			b, ok := parseKey(k)
+			if !ok || b.Site != site {
+				continue
+			}
+			b.Views = binary.BigEndian.Uint64(v)
+			list = append(list, b)
+		}
+		return nil
+	})
+	return list, err
+}
+
+// RouteSummary: 페이지별 사람 조회수와 크롤러별 수집 횟수
+type RouteSummary struct {
+	Route  string
+	Human  uint64
+	Crawls uint64
+	// Crawlers: 크롤러 이름별 수집 횟수
+	Crawlers map[string]uint64
+	// LastCrawled: 크롤러 이름별 마지막 수집 시간(시간 단위)
+	LastCrawled map[string]time.Time
+}
+
+// Summarize: 시간별 조회수를 페이지별로 합침. 사람 조회수가 많은 순서
+func Summarize(buckets []*Bucket) []*RouteSummary {
+	m := map[string]*RouteSummary{}
+	for _, b := range buckets {
+		s, has := m[b.Route]
+		if !has {
+			s = &RouteSummary{Route: b.Route, Crawlers: map[string]uint64{}, LastCrawled: map[string]time.Time{}}
+			m[b.Route] = s
+		}
+		if b.Agent == Human {
+			s.Human += b.Views
+			continue
+		}
+		s.Crawls += b.Views
+		s.Crawlers[b.Agent] += b.Views
+		if b.Hour.After(s.LastCrawled[b.Agent]) {
+			s.LastCrawled[b.Agent] = b.Hour
+		}
+	}
+	list := []*RouteSummary{}
+	for _, s := range m {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Human != list[j].Human {
+			return list[i].Human > list[j].Human
+		}
+		return list[i].Route < list[j].Route
+	})
+	return list
+}
+
+// ReferrerCount: 사람 방문자의 Referrer별 조회수
+type ReferrerCount struct {
+	Referrer string
+	Views    uint64
+}
+
+func TopReferrers(buckets []*Bucket, n int) []*ReferrerCount {
+	m := map[string]uint64{}
+	for _, b := range buckets {
+		if b.Agent == Human && b.Referrer != "" {
+			m[b.Referrer] += b.Views
+		}
+	}
+	list := []*ReferrerCount{}
+	for ref, views := range m {
+		list = append(list, &ReferrerCount{Referrer: ref, Views: views})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Views != list[j].Views {
+			return list[i].Views > list[j].Views
+		}
+		return list[i].Referrer < list[j].Referrer
+	})
+	if len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
@@ -0,0 +1,48 @@
+package analytics
+
+import "strings"
+
+// Human: 크롤러가 아닌 방문자
+const Human = "human"
+
+// crawlers: User-Agent에 포함된 문자열과 크롤러 이름. 앞에서부터 찾음
+var crawlers = []struct {
+	token string
+	name  string
+}{
+	{"googlebot", "Googlebot"},
+	{"adsbot-google", "Googlebot"},
+	{"google-inspectiontool", "Googlebot"},
+	{"yeti", "Naver"},
+	{"daum", "Daum"},
+	{"bingbot", "Bingbot"},
+	{"duckduckbot", "DuckDuckBot"},
+	{"yandex", "Yandex"},
+	{"baiduspider", "Baidu"},
+	{"applebot", "Applebot"},
+	{"facebookexternalhit", "Facebook"},
+	{"twitterbot", "Twitter"},
+	{"kakaotalk-scrap", "Kakao"},
+	{"ahrefsbot", "Ahrefs"},
+	{"semrushbot", "Semrush"},
+}
+
+// Agent: User-Agent가 알려진 크롤러면 크롤러 이름, 아니면 Human.
+// 이름을 모르는 bot, crawler, spider는 "other-bot"
+func Agent(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	for _, c := range crawlers {
+		if strings.Contains(ua, c.token) {
+			return c.name
+		}
+	}
+	for _, token := range []string{"bot", "crawler", "spider", "curl", "wget", "python-requests"} {
+		if strings.Contains(ua, token) {
+			return "other-bot"
+		}
+	}
+	if ua == "" {
+		return "other-bot"
+	}
+	return Human
+}
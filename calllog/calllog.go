@@ -0,0 +1,165 @@
+package calllog
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxFieldBytes: Page, Referrer, UTM 값의 최대 길이. 클라이언트가 보낸 값이므로 넘으면 잘라서 기록
+const MaxFieldBytes = 256
+
+// Click: 전화 연결 버튼 클릭 한 번
+type Click struct {
+	Time time.Time `json:"time"`
+	// Site: 클릭이 일어난 사이트 도메인
+	Site string `json:"site"`
+	// StoreKey: 가게 키. 카테고리 페이지면 do:si:type, 그 외 페이지면 site
+	StoreKey string `json:"storeKey"`
+	Type     string `json:"type,omitempty"`
+	// Page: 버튼이 있던 페이지 경로
+	Page        string `json:"page,omitempty"`
+	Referrer    string `json:"referrer,omitempty"`
+	UTMSource   string `json:"utmSource,omitempty"`
+	UTMMedium   string `json:"utmMedium,omitempty"`
+	UTMCampaign string `json:"utmCampaign,omitempty"`
+	UTMTerm     string `json:"utmTerm,omitempty"`
+	UTMContent  string `json:"utmContent,omitempty"`
+	// Bot: 크롤러 이름(analytics.Agent). 사람이면 빈 값. 리포트 집계에서 제외
+	Bot string `json:"bot,omitempty"`
+}
+
+// clip: s를 MaxFieldBytes 이하로 자름. 글자 중간에서 자르지 않음
+func clip(s string) string {
+	if len(s) <= MaxFieldBytes {
+		return s
+	}
+	s = s[:MaxFieldBytes]
+	for len(s) > 0 && !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+func (c *Click) clip() {
+	for _, v := range []*string{&c.Page, &c.Referrer, &c.UTMSource, &c.UTMMedium, &c.UTMCampaign, &c.UTMTerm, &c.UTMContent} {
+		*v = clip(*v)
+	}
+}
+
+// Log: 클릭을 JSON 한줄씩 파일 끝에 추가. 외부 서비스 없이 동작
+type Log struct {
+	path string
+	// mu: 쓰기끼리만 막음. 읽기는 잘린 마지막 줄을 건너뛰므로 잠그지 않음
+	mu sync.Mutex
+}
+
+func New(path string) *Log { return &Log{path: path} }
+
+func (l *Log) Append(c *Click) error {
+	c.clip()
+	b, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+// Count: 리포트 한 줄. ex) {Key: "쩜오", Clicks: 12}
+type Count struct {
+	Key    string `json:"key"`
+	Clicks int    `json:"clicks"`
+}
+
+type Report struct {
+	From  time.Time `json:"from"`
+	To    time.Time `json:"to"`
+	Total int       `json:"total"`
+	// Bots: 크롤러의 클릭 수. Total과 다른 집계에는 포함하지 않음
+	Bots    int      `json:"bots"`
+	ByStore []*Count `json:"byStore"`
+	ByType  []*Count `json:"byType"`
+	// ByDay: 날짜(YYYY-MM-DD) 순서
+	ByDay []*Count `json:"byDay"`
+}
+
+func sortedCounts(m map[string]int, byKey bool) []*Count {
+	list := []*Count{}
+	for k, n := range m {
+		list = append(list, &Count{Key: k, Clicks: n})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if byKey || list[i].Clicks == list[j].Clicks {
+			return list[i].Key < list[j].Key
+		}
+		return list[i].Clicks > list[j].Clicks
+	})
+	return list
+}
+
+// each: 파일의 클릭을 순서대로 fn에 전달. 파일이 없으면 클릭 없음
+func (l *Log) each(fn func(*Click)) error {
+	f, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		c := &Click{}
+		if err := json.Unmarshal(sc.Bytes(), c); err != nil {
+			// 쓰는 도중 종료되어 잘린 줄은 건너뜀
+			continue
+		}
+		fn(c)
+	}
+	return sc.Err()
+}
+
+// Report: siteDomain 사이트의 [from, to) 기간 클릭을 가게, 업종, 날짜별로 집계.
+// 파일 전체를 읽는 동안 Append를 막지 않도록 잠그지 않음
+func (l *Log) Report(siteDomain string, from, to time.Time) (*Report, error) {
+	r := &Report{From: from, To: to}
+	byStore, byType, byDay := map[string]int{}, map[string]int{}, map[string]int{}
+	err := l.each(func(c *Click) {
+		if c.Site != siteDomain || c.Time.Before(from) || !c.Time.Before(to) {
+			return
+		}
+		if c.Bot != "" {
+			r.Bots++
+			return
+		}
+		r.Total++
+		byStore[c.StoreKey]++
+		if c.Type != "" {
+			byType[c.Type]++
+		}
+		byDay[c.Time.Format("2006-01-02")]++
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.ByStore = sortedCounts(byStore, false)
+	r.ByType = sortedCounts(byType, false)
+	r.ByDay = sortedCounts(byDay, true)
+	return r, nil
+}
@@ -0,0 +1,53 @@
+package calllog
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestClip(t *testing.T) {
+	if got := clip("/store/서울"); got != "/store/서울" {
+		t.Errorf("short value changed: %q", got)
+	}
+	// 한글은 3바이트라 MaxFieldBytes가 글자 중간에 걸림
+	got := clip(strings.Repeat("가", MaxFieldBytes))
+	if len(got) > MaxFieldBytes || !utf8.ValidString(got) {
+		t.Errorf("clip = %d bytes, valid=%v", len(got), utf8.ValidString(got))
+	}
+	if len(got) < MaxFieldBytes-2 {
+		t.Errorf("clip cut too much: %d bytes", len(got))
+	}
+}
+
+func TestReport(t *testing.T) {
+	l := New(filepath.Join(t.TempDir(), "calls.jsonl"))
+	day := time.Date(2024, 6, 1, 12, 0, 0, 0, time.Local)
+	clicks := []*Click{
+		{Time: day, Site: "colagom.com", StoreKey: "a", Type: "쩜오", Referrer: strings.Repeat("x", 10000)},
+		{Time: day, Site: "colagom.com", StoreKey: "a", Type: "쩜오"},
+		{Time: day, Site: "colagom.com", StoreKey: "a", Type: "쩜오", Bot: "googlebot"},
+		{Time: day, Site: "other.com", StoreKey: "a", Type: "쩜오"},
+		{Time: day.AddDate(0, 0, 1), Site: "colagom.com", StoreKey: "b"},
+	}
+	for _, c := range clicks {
+		if err := l.Append(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := len(clicks[0].Referrer); n != MaxFieldBytes {
+		t.Errorf("referrer stored with %d bytes", n)
+	}
+	r, err := l.Report("colagom.com", day.AddDate(0, 0, -1), day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Total != 2 || r.Bots != 1 {
+		t.Errorf("total=%d bots=%d, want 2, 1", r.Total, r.Bots)
+	}
+	if len(r.ByStore) != 1 || r.ByStore[0].Clicks != 2 {
+		t.Errorf("byStore = %+v", r.ByStore)
+	}
+}
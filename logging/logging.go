@@ -0,0 +1,43 @@
+package logging
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/jeonghoikun/colagom.com/site"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// Init: slog 기본 logger를 JSON 형식으로 설정. log.Printf로 남긴 로그도 같은 곳에 INFO로 기록.
+// 반환한 io.Closer는 종료할 때 닫음
+func Init(cfg *site.Log) (io.Closer, error) {
+	if cfg == nil {
+		cfg = &site.Log{}
+	}
+	level := slog.LevelInfo
+	if cfg.Level != "" {
+		if err := level.UnmarshalText([]byte(strings.ToUpper(cfg.Level))); err != nil {
+			return nil, err
+		}
+	}
+	var w io.Writer = os.Stderr
+	var closer io.Closer = nopCloser{}
+	if cfg.Path != "" {
+		f := &lumberjack.Logger{
+			Filename:   cfg.Path,
+			MaxSize:    cfg.MaxSizeMB,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAgeDays,
+			LocalTime:  true,
+		}
+		w, closer = f, f
+	}
+	slog.SetDefault(slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})))
+	return closer, nil
+}
@@ -1,14 +1,23 @@
 package main
 
 import (
+	"context"
+	"io"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/jeonghoikun/colagom.com/logging"
 	"github.com/jeonghoikun/colagom.com/server"
 	"github.com/jeonghoikun/colagom.com/site"
 	"github.com/jeonghoikun/colagom.com/store"
 )
 
+// logFile: 종료할 때 닫는 로그 파일
+var logFile io.Closer
+
 func init() {
 	loc, err := time.LoadLocation("Asia/Seoul")
 	if err != nil {
@@ -16,12 +25,34 @@ func init() {
 	}
 	time.Local = loc
 	site.Init()
+	// 가게 로드 경고도 같은 로그에 남도록 먼저 설정
+	if logFile, err = logging.Init(site.Config.Log); err != nil {
+		panic(err)
+	}
 	if err := store.Init(); err != nil {
 		panic(err)
 	}
 }
 
 func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 	s := server.New(site.Config.Port)
-	log.Fatal(s.Run())
+	errc := make(chan error, 1)
+	go func() { errc <- s.Run() }()
+	select {
+	case err := <-errc:
+		log.Fatal(err)
+	case <-ctx.Done():
+	}
+	// 신호를 한번 더 받으면 기다리지 않고 바로 종료
+	stop()
+	log.Printf("server: shutting down")
+	ctx, cancel := context.WithTimeout(context.Background(), site.Config.ShutdownTimeout)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		log.Fatalf("server: shutdown: %s", err)
+	}
+	log.Printf("server: stopped")
+	logFile.Close()
 }
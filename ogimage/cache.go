@@ -0,0 +1,78 @@
+package ogimage
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Cache: 생성한 이미지를 디스크에 저장. 파일명에 수정일이 들어가므로
+// DateModified가 바뀌면 새 파일을 만들고 이전 파일은 지움
+type Cache struct {
+	dir string
+	mu  sync.Mutex
+	// locks: 같은 이미지를 동시에 만들지 않도록 key별 lock. 다른 key의 요청은 기다리지 않음
+	locks map[string]*keyLock
+}
+
+type keyLock struct {
+	sync.Mutex
+	// refs: 이 lock을 잡았거나 기다리는 요청 수. 0이 되면 locks에서 제거
+	refs int
+}
+
+func NewCache(dir string) *Cache { return &Cache{dir: dir, locks: map[string]*keyLock{}} }
+
+// lock: key의 lock을 잡고 해제 함수를 반환
+func (c *Cache) lock(key string) func() {
+	c.mu.Lock()
+	l, has := c.locks[key]
+	if !has {
+		l = &keyLock{}
+		c.locks[key] = l
+	}
+	l.refs++
+	c.mu.Unlock()
+	l.Lock()
+	return func() {
+		l.Unlock()
+		c.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(c.locks, key)
+		}
+		c.mu.Unlock()
+	}
+}
+
+func (c *Cache) prefix(key string) string {
+	sum := sha1.Sum([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
+
+func (c *Cache) Get(key string, modified time.Time, render func() ([]byte, error)) ([]byte, error) {
+	defer c.lock(key)()
+	prefix := c.prefix(key)
+	path := fmt.Sprintf("%s-%d.png", prefix, modified.Unix())
+	if b, err := os.ReadFile(path); err == nil {
+		return b, nil
+	}
+	b, err := render()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return nil, err
+	}
+	olds, _ := filepath.Glob(prefix + "-*.png")
+	for _, old := range olds {
+		os.Remove(old)
+	}
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
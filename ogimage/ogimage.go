@@ -0,0 +1,194 @@
+package ogimage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+
+	_ "image/jpeg"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	Width  = 1200
+	Height = 630
+
+	padding = 64
+)
+
+// ErrNoFont: LoadFont가 성공하지 않은 상태에서 Render 호출시 반환
+var ErrNoFont = errors.New("ogimage: font is not loaded")
+
+var (
+	colorText    = color.RGBA{0xf1, 0xf5, 0xf9, 0xff}
+	colorSubText = color.RGBA{0xcb, 0xd5, 0xe1, 0xff}
+	colorPrice   = color.RGBA{0xfe, 0xf0, 0x8a, 0xff}
+	colorOpen    = color.RGBA{0x1e, 0x3a, 0x8a, 0xff}
+	colorClosed  = color.RGBA{0x7f, 0x1d, 0x1d, 0xff}
+	colorOverlay = color.RGBA{0x0f, 0x17, 0x2a, 0xb4}
+)
+
+var parsedFont *opentype.Font
+
+// LoadFont: 한글 글리프가 있는 TTF/OTF 폰트 로드. ex) static/font/NanumGothic-Bold.ttf
+func LoadFont(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	f, err := opentype.Parse(b)
+	if err != nil {
+		return err
+	}
+	if i, err := f.GlyphIndex(nil, '가'); err != nil || i == 0 {
+		return fmt.Errorf("%s: 한글 글리프가 없는 폰트입니다", path)
+	}
+	parsedFont = f
+	return nil
+}
+
+type Card struct {
+	// BackgroundPath: 배경으로 쓸 이미지 파일 경로. ex) static/img/store/.../thumbnail.png
+	BackgroundPath string
+	// Title: ex) 에프원
+	Title string
+	// Region: ex) 서울 강남 역삼동
+	Region string
+	// Type: ex) 쩜오
+	Type string
+	// IsClosed: true면 폐업 뱃지, false면 영업중 뱃지
+	IsClosed bool
+	// Badge: 뱃지 문구. ex) 영업중, 폐업
+	Badge string
+	// Price: ex) ₩290,000~
+	Price string
+	// SiteTitle: 우측 하단에 들어갈 사이트 이름
+	SiteTitle string
+}
+
+func face(size float64) (font.Face, error) {
+	return opentype.NewFace(parsedFont, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+}
+
+// fitFace: 문자열이 maxWidth 안에 들어갈 때까지 폰트 크기를 줄임
+func fitFace(s string, size float64, maxWidth int) (font.Face, error) {
+	for {
+		f, err := face(size)
+		if err != nil {
+			return nil, err
+		}
+		if size <= 24 || font.MeasureString(f, s).Ceil() <= maxWidth {
+			return f, nil
+		}
+		f.Close()
+		size -= 4
+	}
+}
+
+func drawText(dst draw.Image, f font.Face, c color.Color, x, y int, s string) int {
+	d := &font.Drawer{Dst: dst, Src: image.NewUniform(c), Face: f, Dot: fixed.P(x, y)}
+	d.DrawString(s)
+	return d.Dot.X.Ceil()
+}
+
+// drawCover: 배경 이미지를 비율 유지한 채로 캔버스를 꽉 채우도록 잘라서 그림
+func drawCover(dst draw.Image, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	src, _, err := image.Decode(file)
+	if err != nil {
+		return err
+	}
+	sb := src.Bounds()
+	crop := sb
+	if sb.Dx()*Height > sb.Dy()*Width {
+		w := sb.Dy() * Width / Height
+		crop.Min.X = sb.Min.X + (sb.Dx()-w)/2
+		crop.Max.X = crop.Min.X + w
+	} else {
+		h := sb.Dx() * Height / Width
+		crop.Min.Y = sb.Min.Y + (sb.Dy()-h)/2
+		crop.Max.Y = crop.Min.Y + h
+	}
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Src, nil)
+	return nil
+}
+
+func Render(c *Card) ([]byte, error) {
+	if parsedFont == nil {
+		return nil, ErrNoFont
+	}
+	canvas := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(colorOverlay), image.Point{}, draw.Src)
+	if c.BackgroundPath != "" {
+		if err := drawCover(canvas, c.BackgroundPath); err != nil {
+			return nil, err
+		}
+	}
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(colorOverlay), image.Point{}, draw.Over)
+
+	// 영업중/폐업 뱃지
+	badgeFace, err := face(36)
+	if err != nil {
+		return nil, err
+	}
+	defer badgeFace.Close()
+	badgeColor := colorOpen
+	if c.IsClosed {
+		badgeColor = colorClosed
+	}
+	badgeWidth := font.MeasureString(badgeFace, c.Badge).Ceil() + 48
+	badge := image.Rect(padding, padding, padding+badgeWidth, padding+64)
+	draw.Draw(canvas, badge, image.NewUniform(badgeColor), image.Point{}, draw.Src)
+	drawText(canvas, badgeFace, colorText, padding+24, padding+45, c.Badge)
+
+	// 가게/카테고리 이름
+	titleFace, err := fitFace(c.Title, 112, Width-padding*2)
+	if err != nil {
+		return nil, err
+	}
+	defer titleFace.Close()
+	drawText(canvas, titleFace, colorText, padding, 320, c.Title)
+
+	// 지역, 업종
+	subFace, err := face(44)
+	if err != nil {
+		return nil, err
+	}
+	defer subFace.Close()
+	drawText(canvas, subFace, colorSubText, padding, 400, c.Region+" · "+c.Type)
+
+	// 시작 가격
+	priceFace, err := face(64)
+	if err != nil {
+		return nil, err
+	}
+	defer priceFace.Close()
+	drawText(canvas, priceFace, colorPrice, padding, Height-padding, c.Price)
+
+	// 사이트 이름
+	siteFace, err := face(32)
+	if err != nil {
+		return nil, err
+	}
+	defer siteFace.Close()
+	w := font.MeasureString(siteFace, c.SiteTitle).Ceil()
+	drawText(canvas, siteFace, colorSubText, Width-padding-w, Height-padding, c.SiteTitle)
+
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, canvas); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
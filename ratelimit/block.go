@@ -0,0 +1,50 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// BlockList: 차단할 IP와 대역
+type BlockList struct {
+	nets []*net.IPNet
+}
+
+// ParseBlockList: ex) []string{"203.0.113.7", "198.51.100.0/24"}
+func ParseBlockList(list []string) (*BlockList, error) {
+	b := &BlockList{}
+	for _, v := range list {
+		v = strings.TrimSpace(v)
+		if !strings.Contains(v, "/") {
+			ip := net.ParseIP(v)
+			if ip == nil {
+				return nil, fmt.Errorf("block list: IP 형식이 아닙니다: %q", v)
+			}
+			bits := 32
+			if ip.To4() == nil {
+				bits = 128
+			}
+			v = fmt.Sprintf("%s/%d", v, bits)
+		}
+		_, n, err := net.ParseCIDR(v)
+		if err != nil {
+			return nil, fmt.Errorf("block list: %w", err)
+		}
+		b.nets = append(b.nets, n)
+	}
+	return b, nil
+}
+
+func (b *BlockList) Contains(ip string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, n := range b.nets {
+		if n.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
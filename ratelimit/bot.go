@@ -0,0 +1,114 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Resolver: DNS 조회. 보통 net.DefaultResolver. 개발 환경이나 테스트에서는 가짜로 바꿀 수 있음
+type Resolver interface {
+	LookupAddr(ctx context.Context, addr string) ([]string, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// botDomains: 크롤러 이름(analytics.Agent)과 역방향 DNS 호스트가 끝나야 하는 도메인
+var botDomains = map[string][]string{
+	"Googlebot": {".googlebot.com", ".google.com", ".googleusercontent.com"},
+	"Bingbot":   {".search.msn.com"},
+	"Naver":     {".naver.com"},
+	"Daum":      {".daum.net", ".kakao.com"},
+	"Applebot":  {".applebot.apple.com"},
+	"Yandex":    {".yandex.ru", ".yandex.net", ".yandex.com"},
+}
+
+const (
+	verifiedTTL   = 24 * time.Hour
+	unverifiedTTL = time.Hour
+	lookupTimeout = 2 * time.Second
+)
+
+type verdict struct {
+	ok      bool
+	expires time.Time
+}
+
+// Verifier: User-Agent가 검색엔진 크롤러라고 하는 요청이 진짜인지 확인.
+// IP의 역방향 DNS 호스트가 검색엔진 도메인이고, 그 호스트의 정방향 DNS에 같은 IP가 있어야 함
+type Verifier struct {
+	resolver Resolver
+	mu       sync.Mutex
+	cache    map[string]*verdict
+}
+
+func NewVerifier(resolver Resolver) *Verifier {
+	return &Verifier{resolver: resolver, cache: map[string]*verdict{}}
+}
+
+// IsKnownBot: 확인할 수 있는 크롤러 이름인지
+func IsKnownBot(agent string) bool {
+	_, has := botDomains[agent]
+	return has
+}
+
+// Verify: agent(analytics.Agent 결과)가 ip에서 온 진짜 크롤러인지. 결과는 일정 시간 저장
+func (v *Verifier) Verify(ip, agent string) bool {
+	domains, has := botDomains[agent]
+	if !has {
+		return false
+	}
+	key := agent + "\x00" + ip
+	now := time.Now()
+	v.mu.Lock()
+	if c, has := v.cache[key]; has && now.Before(c.expires) {
+		v.mu.Unlock()
+		return c.ok
+	}
+	v.mu.Unlock()
+
+	ok := v.lookup(ip, domains)
+	ttl := unverifiedTTL
+	if ok {
+		ttl = verifiedTTL
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for k, c := range v.cache {
+		if now.After(c.expires) {
+			delete(v.cache, k)
+		}
+	}
+	v.cache[key] = &verdict{ok: ok, expires: now.Add(ttl)}
+	return ok
+}
+
+func (v *Verifier) lookup(ip string, domains []string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+	defer cancel()
+	names, err := v.resolver.LookupAddr(ctx, ip)
+	if err != nil {
+		return false
+	}
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSuffix(name, "."))
+		matched := false
+		for _, d := range domains {
+			matched = matched || strings.HasSuffix(name, d)
+		}
+		if !matched {
+			continue
+		}
+		addrs, err := v.resolver.LookupHost(ctx, name)
+		if err != nil {
+			continue
+		}
+		for _, a := range addrs {
+			if net.ParseIP(a).Equal(net.ParseIP(ip)) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// fakeResolver: addr => 역방향 호스트, host => 정방향 IP
+type fakeResolver struct {
+	addrs   map[string][]string
+	hosts   map[string][]string
+	lookups int
+}
+
+func (r *fakeResolver) LookupAddr(_ context.Context, addr string) ([]string, error) {
+	r.lookups++
+	names, has := r.addrs[addr]
+	if !has {
+		return nil, errors.New("no PTR record")
+	}
+	return names, nil
+}
+
+func (r *fakeResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	addrs, has := r.hosts[host]
+	if !has {
+		return nil, errors.New("no such host")
+	}
+	return addrs, nil
+}
+
+func TestVerifierVerify(t *testing.T) {
+	r := &fakeResolver{
+		addrs: map[string][]string{
+			"66.249.66.1":  {"crawl-66-249-66-1.googlebot.com."},
+			"203.0.113.5":  {"crawl.googlebot.com.evil.example."},
+			"198.51.100.7": {"spoof.googlebot.com."},
+			"2001:db8::1":  {"msnbot-1.search.msn.com."},
+			"192.0.2.9":    {"Crawl.Naver.com."},
+		},
+		hosts: map[string][]string{
+			"crawl-66-249-66-1.googlebot.com":  {"66.249.66.1"},
+			"crawl.googlebot.com.evil.example": {"203.0.113.5"},
+			// 역방향은 googlebot.com이지만 정방향은 다른 IP
+			"spoof.googlebot.com":     {"66.249.66.2"},
+			"msnbot-1.search.msn.com": {"2001:0db8:0000:0000:0000:0000:0000:0001"},
+			"crawl.naver.com":         {"192.0.2.9"},
+		},
+	}
+	tests := []struct {
+		name  string
+		ip    string
+		agent string
+		want  bool
+	}{
+		{"forward confirmed", "66.249.66.1", "Googlebot", true},
+		{"domain suffix only", "203.0.113.5", "Googlebot", false},
+		{"forward mismatch", "198.51.100.7", "Googlebot", false},
+		{"no PTR record", "192.0.2.1", "Googlebot", false},
+		{"wrong bot for domain", "66.249.66.1", "Bingbot", false},
+		{"ipv6 equal form", "2001:db8::1", "Bingbot", true},
+		{"case and trailing dot", "192.0.2.9", "Naver", true},
+		{"unknown agent", "66.249.66.1", "Chrome", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewVerifier(r).Verify(tt.ip, tt.agent); got != tt.want {
+				t.Errorf("Verify(%q, %q) = %v, want %v", tt.ip, tt.agent, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifierCache(t *testing.T) {
+	r := &fakeResolver{
+		addrs: map[string][]string{"66.249.66.1": {"crawl.googlebot.com."}},
+		hosts: map[string][]string{"crawl.googlebot.com": {"66.249.66.1"}},
+	}
+	v := NewVerifier(r)
+	for i := 0; i < 3; i++ {
+		if !v.Verify("66.249.66.1", "Googlebot") {
+			t.Fatal("Verify = false, want true")
+		}
+		v.Verify("192.0.2.1", "Googlebot")
+	}
+	if r.lookups != 2 {
+		t.Errorf("lookups = %d, want 2 (결과를 저장해야 함)", r.lookups)
+	}
+}
+
+func TestIsKnownBot(t *testing.T) {
+	for agent, want := range map[string]bool{"Googlebot": true, "Yandex": true, "Chrome": false, "": false} {
+		if got := IsKnownBot(agent); got != want {
+			t.Errorf("IsKnownBot(%q) = %v, want %v", agent, got, want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Backend: 토큰 버킷 저장소. 여러 서버가 같은 카운터를 쓰려면 Redis 등으로 구현
+type Backend interface {
+	// Take: key 버킷에서 토큰 하나를 사용. 버킷에는 초당 rate개씩 최대 burst개까지 토큰이 찬다.
+	// 토큰이 없으면 ok=false와 다음 토큰이 찰 때까지 남은 시간
+	Take(key string, rate float64, burst int, now time.Time) (ok bool, retryAfter time.Duration, err error)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// full: 요청이 없어도 버킷이 가득 차는 시간. 지나면 버킷을 지워도 결과가 같음
+	full time.Time
+}
+
+// Memory: 프로세스 메모리에 버킷을 두는 Backend
+type Memory struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{buckets: map[string]*bucket{}}
+}
+
+// sweepInterval: 가득 찬 버킷을 지우는 주기
+const sweepInterval = time.Minute
+
+func (m *Memory) Take(key string, rate float64, burst int, now time.Time) (bool, time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if now.Sub(m.lastSweep) > sweepInterval {
+		for k, b := range m.buckets {
+			if now.After(b.full) {
+				delete(m.buckets, k)
+			}
+		}
+		m.lastSweep = now
+	}
+	b, has := m.buckets[key]
+	if !has {
+		b = &bucket{tokens: float64(burst), last: now}
+		m.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+		return false, wait, nil
+	}
+	b.tokens--
+	b.full = now.Add(time.Duration((float64(burst) - b.tokens) / rate * float64(time.Second)))
+	return true, 0, nil
+}
+
+// Len: 메모리에 있는 버킷 수
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.buckets)
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryTake(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	type take struct {
+		after time.Duration
+		ok    bool
+		retry time.Duration
+	}
+	tests := []struct {
+		name  string
+		rate  float64
+		burst int
+		takes []take
+	}{
+		{
+			name: "burst then empty", rate: 1, burst: 3,
+			takes: []take{{0, true, 0}, {0, true, 0}, {0, true, 0}, {0, false, time.Second}},
+		},
+		{
+			name: "partial refill", rate: 1, burst: 1,
+			takes: []take{{0, true, 0}, {500 * time.Millisecond, false, 500 * time.Millisecond}, {time.Second, true, 0}},
+		},
+		{
+			name: "slow rate", rate: 0.1, burst: 1,
+			takes: []take{{0, true, 0}, {0, false, 10 * time.Second}, {9 * time.Second, false, time.Second}, {10 * time.Second, true, 0}},
+		},
+		{
+			name: "idle refill is capped at burst", rate: 1, burst: 2,
+			takes: []take{{0, true, 0}, {time.Hour, true, 0}, {time.Hour, true, 0}, {time.Hour, false, time.Second}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMemory()
+			for i, x := range tt.takes {
+				ok, retry, err := m.Take("k", tt.rate, tt.burst, t0.Add(x.after))
+				if err != nil {
+					t.Fatal(err)
+				}
+				// retryAfter는 float 계산이므로 1ms까지 허용
+				if d := retry - x.retry; ok != x.ok || d > time.Millisecond || d < -time.Millisecond {
+					t.Errorf("take %d: got (%v, %s), want (%v, %s)", i, ok, retry, x.ok, x.retry)
+				}
+			}
+		})
+	}
+}
+
+func TestMemorySweep(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewMemory()
+	m.Take("full", 1, 1, t0)
+	m.Take("empty", 0.001, 5, t0)
+	m.Take("empty", 0.001, 5, t0)
+	if n := m.Len(); n != 2 {
+		t.Fatalf("Len = %d, want 2", n)
+	}
+	// sweepInterval 안에서는 지우지 않음
+	m.Take("other", 1, 1, t0.Add(sweepInterval/2))
+	if n := m.Len(); n != 3 {
+		t.Fatalf("Len = %d, want 3", n)
+	}
+	// full, other는 가득 찼으므로 지우고 empty(1000초 이상 걸림)는 남김
+	m.Take("new", 1, 1, t0.Add(2*sweepInterval))
+	if n := m.Len(); n != 2 {
+		t.Errorf("Len after sweep = %d, want 2", n)
+	}
+	// 지운 버킷은 가득 찬 상태로 다시 시작
+	if ok, _, _ := m.Take("full", 1, 1, t0.Add(2*sweepInterval)); !ok {
+		t.Error("swept bucket should start full")
+	}
+}
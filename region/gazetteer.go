@@ -0,0 +1,59 @@
+package region
+
+// gazetteer: 지역 하드코딩. 법정동 코드는 행정표준코드관리시스템 기준.
+// 상위 지역을 먼저 입력. 가게를 새 지역에 추가하려면 여기에 먼저 추가
+var gazetteer = []*Region{
+	{Code: "1100000000", Name: "서울", Official: "서울특별시", Short: "서울", English: "Seoul", Level: Do},
+
+	{Code: "1111000000", Name: "종로구", Short: "종로", English: "Jongno-gu", Level: Si},
+	{Code: "1114000000", Name: "중구", English: "Jung-gu", Level: Si},
+	{Code: "1117000000", Name: "용산구", Short: "용산", English: "Yongsan-gu", Level: Si},
+	{Code: "1120000000", Name: "성동구", Short: "성동", English: "Seongdong-gu", Level: Si},
+	{Code: "1121500000", Name: "광진구", Short: "광진", English: "Gwangjin-gu", Level: Si},
+	{Code: "1123000000", Name: "동대문구", Short: "동대문", English: "Dongdaemun-gu", Level: Si},
+	{Code: "1126000000", Name: "중랑구", Short: "중랑", English: "Jungnang-gu", Level: Si},
+	{Code: "1129000000", Name: "성북구", Short: "성북", English: "Seongbuk-gu", Level: Si},
+	{Code: "1130500000", Name: "강북구", Short: "강북", English: "Gangbuk-gu", Level: Si},
+	{Code: "1132000000", Name: "도봉구", Short: "도봉", English: "Dobong-gu", Level: Si},
+	{Code: "1135000000", Name: "노원구", Short: "노원", English: "Nowon-gu", Level: Si},
+	{Code: "1138000000", Name: "은평구", Short: "은평", English: "Eunpyeong-gu", Level: Si},
+	{Code: "1141000000", Name: "서대문구", Short: "서대문", English: "Seodaemun-gu", Level: Si},
+	{Code: "1144000000", Name: "마포구", Short: "마포", English: "Mapo-gu", Level: Si},
+	{Code: "1147000000", Name: "양천구", Short: "양천", English: "Yangcheon-gu", Level: Si},
+	{Code: "1150000000", Name: "강서구", Short: "강서", English: "Gangseo-gu", Level: Si},
+	{Code: "1153000000", Name: "구로구", Short: "구로", English: "Guro-gu", Level: Si},
+	{Code: "1154500000", Name: "금천구", Short: "금천", English: "Geumcheon-gu", Level: Si},
+	{Code: "1156000000", Name: "영등포구", Short: "영등포", English: "Yeongdeungpo-gu", Level: Si},
+	{Code: "1159000000", Name: "동작구", Short: "동작", English: "Dongjak-gu", Level: Si},
+	{Code: "1162000000", Name: "관악구", Short: "관악", English: "Gwanak-gu", Level: Si},
+	{Code: "1165000000", Name: "서초구", Short: "서초", English: "Seocho-gu", Level: Si},
+	{Code: "1168000000", Name: "강남구", Short: "강남", English: "Gangnam-gu", Level: Si},
+	{Code: "1171000000", Name: "송파구", Short: "송파", English: "Songpa-gu", Level: Si},
+	{Code: "1174000000", Name: "강동구", Short: "강동", English: "Gangdong-gu", Level: Si},
+
+	{Code: "1165010100", Name: "방배동", Short: "방배", English: "Bangbae-dong", Level: Dong},
+	{Code: "1165010200", Name: "양재동", Short: "양재", English: "Yangjae-dong", Level: Dong},
+	{Code: "1165010300", Name: "우면동", Short: "우면", English: "Umyeon-dong", Level: Dong},
+	{Code: "1165010400", Name: "원지동", Short: "원지", English: "Wonji-dong", Level: Dong},
+	{Code: "1165010600", Name: "잠원동", Short: "잠원", English: "Jamwon-dong", Level: Dong},
+	{Code: "1165010700", Name: "반포동", Short: "반포", English: "Banpo-dong", Level: Dong},
+	{Code: "1165010800", Name: "서초동", Short: "서초", English: "Seocho-dong", Level: Dong},
+	{Code: "1165010900", Name: "내곡동", Short: "내곡", English: "Naegok-dong", Level: Dong},
+	{Code: "1165011000", Name: "염곡동", Short: "염곡", English: "Yeomgok-dong", Level: Dong},
+	{Code: "1165011100", Name: "신원동", Short: "신원", English: "Sinwon-dong", Level: Dong},
+
+	{Code: "1168010100", Name: "역삼동", Short: "역삼", English: "Yeoksam-dong", Level: Dong},
+	{Code: "1168010300", Name: "개포동", Short: "개포", English: "Gaepo-dong", Level: Dong},
+	{Code: "1168010400", Name: "청담동", Short: "청담", English: "Cheongdam-dong", Level: Dong},
+	{Code: "1168010500", Name: "삼성동", Short: "삼성", English: "Samseong-dong", Level: Dong},
+	{Code: "1168010600", Name: "대치동", Short: "대치", English: "Daechi-dong", Level: Dong},
+	{Code: "1168010700", Name: "신사동", Short: "신사", English: "Sinsa-dong", Level: Dong},
+	{Code: "1168010800", Name: "논현동", Short: "논현", English: "Nonhyeon-dong", Level: Dong},
+	{Code: "1168011000", Name: "압구정동", Short: "압구정", English: "Apgujeong-dong", Level: Dong},
+	{Code: "1168011100", Name: "세곡동", Short: "세곡", English: "Segok-dong", Level: Dong},
+	{Code: "1168011200", Name: "자곡동", Short: "자곡", English: "Jagok-dong", Level: Dong},
+	{Code: "1168011300", Name: "율현동", Short: "율현", English: "Yulhyeon-dong", Level: Dong},
+	{Code: "1168011400", Name: "일원동", Short: "일원", English: "Irwon-dong", Level: Dong},
+	{Code: "1168011500", Name: "수서동", Short: "수서", English: "Suseo-dong", Level: Dong},
+	{Code: "1168011800", Name: "도곡동", Short: "도곡", English: "Dogok-dong", Level: Dong},
+}
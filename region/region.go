@@ -0,0 +1,133 @@
+package region
+
+import "strings"
+
+// Level: 행정구역 단계
+type Level int
+
+const (
+	// Do: 특별시, 광역시, 도. ex) 서울
+	Do Level = iota + 1
+	// Si: 시, 군, 구. ex) 강남구
+	Si
+	// Dong: 법정동, 읍, 면. ex) 역삼동
+	Dong
+)
+
+// Region: 법정동 코드로 구분하는 행정구역
+type Region struct {
+	// Code: 법정동 코드 10자리. ex) 1168010100
+	Code string
+	// Name: URL, 가게 Key, 파일 경로에 쓰는 이름. ex) 서울, 강남구, 역삼동
+	Name string
+	// Official: 행정구역 공식 이름. ex) 서울특별시
+	Official string
+	// Short: 제목, 빵부스러기에 쓰는 짧은 이름. ex) 강남구 => 강남, 중구 => 중구
+	Short string
+	// English: 로마자 표기. ex) Gangnam-gu
+	English string
+	Level   Level
+	// Parent: 상위 지역. 시, 도는 nil
+	Parent *Region
+}
+
+// parentCode: 법정동 코드에서 상위 지역 코드. 시도 2자리, 시군구 3자리, 읍면동 3자리, 리 2자리
+func parentCode(code string, level Level) string {
+	switch level {
+	case Si:
+		return code[:2] + "00000000"
+	case Dong:
+		return code[:5] + "00000"
+	}
+	return ""
+}
+
+var (
+	regions = []*Region{}
+	byCode  = map[string]*Region{}
+)
+
+func init() {
+	for _, r := range gazetteer {
+		if r.Short == "" {
+			r.Short = r.Name
+		}
+		if r.Official == "" {
+			r.Official = r.Name
+		}
+		if p := parentCode(r.Code, r.Level); p != "" {
+			r.Parent = byCode[p]
+		}
+		regions = append(regions, r)
+		byCode[r.Code] = r
+	}
+}
+
+// Get: 법정동 코드로 지역 찾기
+func Get(code string) (*Region, bool) {
+	r, has := byCode[code]
+	return r, has
+}
+
+// Find: 시도부터 차례로 이름(Name 또는 Official)을 따라 내려가 마지막 지역을 찾음.
+// ex) Find("서울", "강남구") => 강남구, Find("서울특별시", "강남구", "역삼동") => 역삼동
+func Find(names ...string) (*Region, bool) {
+	var parent *Region
+	for _, name := range names {
+		var found *Region
+		for _, r := range Children(parent) {
+			if r.Name == name || r.Official == name {
+				found = r
+				break
+			}
+		}
+		if found == nil {
+			return nil, false
+		}
+		parent = found
+	}
+	return parent, parent != nil
+}
+
+// Children: parent 바로 아래 지역. nil이면 시, 도 목록
+func Children(parent *Region) []*Region {
+	list := []*Region{}
+	for _, r := range regions {
+		if r.Parent == parent {
+			list = append(list, r)
+		}
+	}
+	return list
+}
+
+// Ancestor: level 단계의 상위 지역(자기 자신 포함). 없으면 nil.
+// ex) 역삼동.Ancestor(Si) => 강남구
+func (r *Region) Ancestor(level Level) *Region {
+	for x := r; x != nil; x = x.Parent {
+		if x.Level == level {
+			return x
+		}
+	}
+	return nil
+}
+
+// FullName: 시도부터 공식 이름. ex) 서울특별시 강남구 역삼동
+func (r *Region) FullName() string {
+	names := []string{}
+	for x := r; x != nil; x = x.Parent {
+		names = append([]string{x.Official}, names...)
+	}
+	return strings.Join(names, " ")
+}
+
+// ShortName: 이름으로 찾은 지역의 짧은 이름. 없는 지역이면 마지막 이름 그대로.
+// ex) ShortName("서울", "강남구") => 강남, ShortName("서울", "중구") => 중구
+func ShortName(names ...string) string {
+	if r, has := Find(names...); has {
+		return r.Short
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return names[len(names)-1]
+}
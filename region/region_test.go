@@ -0,0 +1,74 @@
+package region
+
+import "testing"
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		names []string
+		code  string
+	}{
+		{[]string{"서울"}, "1100000000"},
+		{[]string{"서울특별시"}, "1100000000"},
+		{[]string{"서울", "강남구"}, "1168000000"},
+		{[]string{"서울특별시", "강남구", "역삼동"}, "1168010100"},
+		{[]string{"서울", "서초구", "잠원동"}, "1165010600"},
+		{[]string{"서울", "강남구", "신사동"}, "1168010700"},
+		// 이름이 같은 구/동은 상위 지역으로 구분
+		{[]string{"서울", "서초구", "서초동"}, "1165010800"},
+		{[]string{"서울", "강남구", "잠원동"}, ""},
+		{[]string{"강남구"}, ""},
+		{[]string{"서울", "강남"}, ""},
+		{[]string{"부산"}, ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		r, has := Find(tt.names...)
+		if tt.code == "" {
+			if has {
+				t.Errorf("Find(%q) = %s, want not found", tt.names, r.Code)
+			}
+			continue
+		}
+		if !has || r.Code != tt.code {
+			t.Errorf("Find(%q) = %v, %v, want %s", tt.names, r, has, tt.code)
+		}
+	}
+}
+
+func TestShortName(t *testing.T) {
+	tests := []struct {
+		names []string
+		want  string
+	}{
+		{[]string{"서울", "강남구"}, "강남"},
+		{[]string{"서울", "서초구"}, "서초"},
+		{[]string{"서울", "중구"}, "중구"},
+		{[]string{"서울"}, "서울"},
+		{[]string{"서울", "강남구", "역삼동"}, "역삼"},
+		// 없는 지역은 마지막 이름 그대로
+		{[]string{"부산", "해운대구"}, "해운대구"},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := ShortName(tt.names...); got != tt.want {
+			t.Errorf("ShortName(%q) = %q, want %q", tt.names, got, tt.want)
+		}
+	}
+}
+
+func TestGazetteerCodes(t *testing.T) {
+	seen := map[string]bool{}
+	for _, r := range gazetteer {
+		if len(r.Code) != 10 {
+			t.Errorf("%s: 법정동 코드는 10자리: %q", r.Name, r.Code)
+		}
+		if seen[r.Code] {
+			t.Errorf("%s: 중복된 코드 %s", r.Name, r.Code)
+		}
+		seen[r.Code] = true
+		// 상위 지역을 먼저 입력해야 Parent가 채워짐
+		if r.Level != Do && (r.Parent == nil || r.Parent.Level != r.Level-1) {
+			t.Errorf("%s(%s): 상위 지역이 없습니다", r.Name, r.Code)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package review
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+type Status string
+
+const (
+	// Pending: 관리자 확인 전. 가게 페이지에 보이지 않음
+	Pending  Status = "pending"
+	Approved Status = "approved"
+	Rejected Status = "rejected"
+)
+
+const (
+	MaxNameLength = 20
+	MinBodyLength = 5
+	MaxBodyLength = 1000
+)
+
+var (
+	ErrNotFound = errors.New("review not found")
+
+	bucketReviews = []byte("reviews")
+)
+
+// Review: 방문자가 남긴 가게 평점과 후기
+type Review struct {
+	ID string `json:"id"`
+	// StoreKey: ex) 서울:강남구:역삼동:쩜오:에이원
+	StoreKey string `json:"storeKey"`
+	// Rating: 1~5
+	Rating int `json:"rating"`
+	// Name: 작성자 닉네임
+	Name      string    `json:"name"`
+	Body      string    `json:"body"`
+	Status    Status    `json:"status"`
+	Created   time.Time `json:"created"`
+	Moderated time.Time `json:"moderated,omitempty"`
+	// Moderator: 승인, 거절한 관리자 계정
+	Moderator string `json:"moderator,omitempty"`
+}
+
+// Validate: 작성자가 입력한 값 검사
+func (r *Review) Validate() error {
+	if r.Rating < 1 || r.Rating > 5 {
+		return errors.New("평점은 1~5 사이로 선택하세요")
+	}
+	if r.Name == "" {
+		return errors.New("닉네임을 입력하세요")
+	}
+	if utf8.RuneCountInString(r.Name) > MaxNameLength {
+		return fmt.Errorf("닉네임은 %d자 이하로 입력하세요", MaxNameLength)
+	}
+	if n := utf8.RuneCountInString(r.Body); n < MinBodyLength || n > MaxBodyLength {
+		return fmt.Errorf("후기는 %d~%d자로 입력하세요", MinBodyLength, MaxBodyLength)
+	}
+	return nil
+}
+
+// Rating: 가게의 승인된 후기 평균
+type Rating struct {
+	Count   int
+	Average float64
+}
+
+// Stars: 소수점 한자리 평균. ex) 4.3
+func (r *Rating) Stars() string { return strconv.FormatFloat(r.Average, 'f', 1, 64) }
+
+// DB: 후기 저장소. 승인된 후기와 평균은 메모리에 두고 승인, 거절할 때 다시 계산
+type DB struct {
+	db *bolt.DB
+
+	mu       sync.RWMutex
+	approved map[string][]*Review
+	ratings  map[string]*Rating
+	// modified: 마지막으로 승인, 거절한 시간. ETag에 사용
+	modified time.Time
+
+	subscribers []func(storeKey string)
+}
+
+func Open(path string) (*DB, error) {
+	db, err := bolt.Open(path, 0o644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketReviews)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	d := &DB{db: db}
+	if err := d.index(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *DB) Close() error { return d.db.Close() }
+
+// newID: 작성 시간 순으로 정렬되는 ID
+func newID(t time.Time) string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return fmt.Sprintf("%016x%s", t.UnixNano(), hex.EncodeToString(b))
+}
+
+func (d *DB) put(tx *bolt.Tx, r *Review) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(bucketReviews).Put([]byte(r.ID), b)
+}
+
+func (d *DB) each(fn func(r *Review)) error {
+	return d.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketReviews).ForEach(func(k, v []byte) error {
+			r := &Review{}
+			if err := json.Unmarshal(v, r); err != nil {
+				return fmt.Errorf("review %s: %w", k, err)
+			}
+			fn(r)
+			return nil
+		})
+	})
+}
+
+// index: 승인된 후기 목록과 평균 다시 계산
+func (d *DB) index() error {
+	approved := map[string][]*Review{}
+	var modified time.Time
+	err := d.each(func(r *Review) {
+		if r.Moderated.After(modified) {
+			modified = r.Moderated
+		}
+		if r.Status == Approved {
+			approved[r.StoreKey] = append(approved[r.StoreKey], r)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	ratings := map[string]*Rating{}
+	for key, list := range approved {
+		sum := 0
+		for _, r := range list {
+			sum += r.Rating
+		}
+		ratings[key] = &Rating{Count: len(list), Average: float64(sum) / float64(len(list))}
+		// 최근 작성한 후기부터
+		sort.Slice(list, func(i, j int) bool { return list[i].ID > list[j].ID })
+	}
+	d.mu.Lock()
+	d.approved, d.ratings, d.modified = approved, ratings, modified
+	d.mu.Unlock()
+	return nil
+}
+
+// Submit: 새 후기를 검사 대기(Pending) 상태로 저장
+func (d *DB) Submit(r *Review) error {
+	r.Name = strings.TrimSpace(r.Name)
+	r.Body = strings.TrimSpace(strings.ReplaceAll(r.Body, "\r\n", "\n"))
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	r.Created = time.Now()
+	r.ID = newID(r.Created)
+	r.Status = Pending
+	r.Moderated, r.Moderator = time.Time{}, ""
+	return d.db.Update(func(tx *bolt.Tx) error { return d.put(tx, r) })
+}
+
+// Moderate: 후기 승인 또는 거절. 가게 페이지에 보이는 내용이 바뀌면 구독자에게 알림
+func (d *DB) Moderate(id string, status Status, actor string) (*Review, error) {
+	if status != Approved && status != Rejected {
+		return nil, fmt.Errorf("알 수 없는 상태입니다: %q", status)
+	}
+	r := &Review{}
+	var prev Status
+	err := d.db.Update(func(tx *bolt.Tx) error {
+		v := tx.Bucket(bucketReviews).Get([]byte(id))
+		if v == nil {
+			return ErrNotFound
+		}
+		if err := json.Unmarshal(v, r); err != nil {
+			return err
+		}
+		prev = r.Status
+		r.Status, r.Moderated, r.Moderator = status, time.Now(), actor
+		return d.put(tx, r)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := d.index(); err != nil {
+		return nil, err
+	}
+	if prev == Approved || status == Approved {
+		d.notify(r.StoreKey)
+	}
+	return r, nil
+}
+
+// StoreKeys: 후기가 있는 가게 Key. 상태와 관계없이 모두
+func (d *DB) StoreKeys() ([]string, error) {
+	seen := map[string]bool{}
+	keys := []string{}
+	err := d.each(func(r *Review) {
+		if !seen[r.StoreKey] {
+			seen[r.StoreKey] = true
+			keys = append(keys, r.StoreKey)
+		}
+	})
+	sort.Strings(keys)
+	return keys, err
+}
+
+// Rekey: 가게 Key가 바뀌면(지역, 업종, 상호 변경) oldKey 가게의 후기를 newKey로 옮김. 옮긴 후기 수 반환
+func (d *DB) Rekey(oldKey, newKey string) (int, error) {
+	if oldKey == newKey {
+		return 0, nil
+	}
+	n := 0
+	err := d.db.Update(func(tx *bolt.Tx) error {
+		list := []*Review{}
+		// ForEach 안에서는 bucket을 수정할 수 없으므로 모은 뒤 저장
+		err := tx.Bucket(bucketReviews).ForEach(func(k, v []byte) error {
+			r := &Review{}
+			if err := json.Unmarshal(v, r); err != nil {
+				return fmt.Errorf("review %s: %w", k, err)
+			}
+			if r.StoreKey == oldKey {
+				list = append(list, r)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, r := range list {
+			r.StoreKey = newKey
+			if err := d.put(tx, r); err != nil {
+				return err
+			}
+		}
+		n = len(list)
+		return nil
+	})
+	if err != nil || n == 0 {
+		return 0, err
+	}
+	if err := d.index(); err != nil {
+		return n, err
+	}
+	d.notify(oldKey)
+	d.notify(newKey)
+	return n, nil
+}
+
+// List: status 후기 전체. 빈 값이면 모든 후기. 최근 작성한 후기부터
+func (d *DB) List(status Status) ([]*Review, error) {
+	list := []*Review{}
+	err := d.each(func(r *Review) {
+		if status == "" || r.Status == status {
+			list = append(list, r)
+		}
+	})
+	sort.Slice(list, func(i, j int) bool { return list[i].ID > list[j].ID })
+	return list, err
+}
+
+// Approved: 가게 페이지에 보여줄 후기. nil DB(후기 기능 꺼짐)면 빈 목록
+func (d *DB) Approved(storeKey string) []*Review {
+	if d == nil {
+		return nil
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.approved[storeKey]
+}
+
+// Rating: 가게 평균 평점. 승인된 후기가 없으면 nil
+func (d *DB) Rating(storeKey string) *Rating {
+	if d == nil {
+		return nil
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.ratings[storeKey]
+}
+
+// Version: 승인, 거절할 때마다 바뀌는 값. HTTP ETag에 사용
+func (d *DB) Version() string {
+	if d == nil {
+		return ""
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.modified.IsZero() {
+		return "0"
+	}
+	return strconv.FormatInt(d.modified.UnixNano(), 36)
+}
+
+// Subscribe: 가게의 승인된 후기가 바뀌면 fn(storeKey) 호출
+func (d *DB) Subscribe(fn func(storeKey string)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.subscribers = append(d.subscribers, fn)
+}
+
+func (d *DB) notify(storeKey string) {
+	d.mu.RLock()
+	subscribers := d.subscribers
+	d.mu.RUnlock()
+	for _, fn := range subscribers {
+		fn(storeKey)
+	}
+}
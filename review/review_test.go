@@ -0,0 +1,53 @@
+package review
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRekey(t *testing.T) {
+	d, err := Open(filepath.Join(t.TempDir(), "reviews.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	const oldKey, newKey, other = "서울:강남구:잠원동:쩜오:에이원", "서울:서초구:잠원동:쩜오:에이원", "서울:강남구:역삼동:쩜오:에프원"
+	for _, r := range []*Review{
+		{StoreKey: oldKey, Rating: 5, Name: "a", Body: "좋았습니다 다음에도"},
+		{StoreKey: oldKey, Rating: 3, Name: "b", Body: "보통이었습니다"},
+		{StoreKey: other, Rating: 4, Name: "c", Body: "괜찮았습니다"},
+	} {
+		if err := d.Submit(r); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := d.Moderate(r.ID, Approved, "admin"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	notified := map[string]bool{}
+	d.Subscribe(func(key string) { notified[key] = true })
+
+	n, err := d.Rekey(oldKey, newKey)
+	if err != nil || n != 2 {
+		t.Fatalf("Rekey = %d, %v, want 2", n, err)
+	}
+	if got := d.Rating(newKey); got == nil || got.Count != 2 || got.Average != 4 {
+		t.Errorf("Rating(new) = %+v", got)
+	}
+	if d.Rating(oldKey) != nil || len(d.Approved(oldKey)) != 0 {
+		t.Error("reviews left under the old key")
+	}
+	if got := d.Rating(other); got == nil || got.Count != 1 {
+		t.Errorf("Rating(other) = %+v", got)
+	}
+	if !notified[oldKey] || !notified[newKey] || notified[other] {
+		t.Errorf("notified = %v", notified)
+	}
+	if n, err := d.Rekey(oldKey, newKey); err != nil || n != 0 {
+		t.Errorf("second Rekey = %d, %v", n, err)
+	}
+	keys, err := d.StoreKeys()
+	if err != nil || len(keys) != 2 {
+		t.Errorf("StoreKeys = %v, %v", keys, err)
+	}
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/session"
+	"github.com/jeonghoikun/colagom.com/site"
+)
+
+// localsAdminUser: 로그인한 관리자 계정. 변경 기록에 남김
+const localsAdminUser = "username"
+
+func newAdminSessions(cfg *site.Site) *session.Store {
+	scheme := cfg.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+	return session.New(session.Config{
+		Expiration:     12 * time.Hour,
+		KeyLookup:      "cookie:colagom_admin",
+		CookiePath:     "/admin",
+		CookieHTTPOnly: true,
+		// https 사이트의 세션 쿠키는 http 요청에 붙지 않음
+		CookieSecure: scheme == "https",
+		// 다른 사이트에서 보낸 form 요청에는 쿠키가 붙지 않음 (CSRF 방지)
+		CookieSameSite: "Strict",
+	})
+}
+
+func checkAdminPassword(user, password string) bool {
+	u := subtle.ConstantTimeCompare([]byte(user), []byte(site.Config.AdminUser))
+	p := subtle.ConstantTimeCompare([]byte(password), []byte(site.Config.AdminPassword))
+	return u&p == 1
+}
+
+// headerAdminClient: basic auth를 쓰는 스크립트가 보내야 하는 헤더. ex) curl -u admin:pw -H "X-Admin-Client: 1"
+// 브라우저는 다른 사이트에서 이 헤더를 붙여 보낼 수 없음
+const headerAdminClient = "X-Admin-Client"
+
+// basicAuth: Authorization: Basic 헤더. 스크립트(curl 등)에서만 사용.
+// 브라우저는 basic auth 계정을 기억해 다른 사이트의 form 요청에도 붙이므로(SameSite 쿠키로 막는 CSRF 우회)
+// headerAdminClient가 없거나 다른 사이트에서 온 요청이면 사용하지 않음
+func basicAuth(c *fiber.Ctx) (user, password string, ok bool) {
+	if c.Get(headerAdminClient) == "" || c.Get(fiber.HeaderOrigin) != "" || c.Get("Sec-Fetch-Site") == "cross-site" {
+		return "", "", false
+	}
+	h := c.Get(fiber.HeaderAuthorization)
+	if !strings.HasPrefix(h, "Basic ") {
+		return "", "", false
+	}
+	b, err := base64.StdEncoding.DecodeString(h[len("Basic "):])
+	if err != nil {
+		return "", "", false
+	}
+	user, password, ok = strings.Cut(string(b), ":")
+	return
+}
+
+func adminUser(c *fiber.Ctx) string {
+	u, _ := c.Locals(localsAdminUser).(string)
+	return u
+}
+
+// safeNext: 로그인 후 이동할 경로. 다른 사이트로 보내지 않도록 /admin 아래만 허용
+func safeNext(next string) string {
+	if !strings.HasPrefix(next, "/admin") || strings.HasPrefix(next, "//") {
+		return "/admin"
+	}
+	return next
+}
+
+// requireLogin: 세션 또는 basic auth로 로그인해야 다음 핸들러 실행
+func (h *adminHandler) requireLogin(c *fiber.Ctx) error {
+	sess, err := h.sessions.Get(c)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).SendString(err.Error())
+	}
+	if u, ok := sess.Get("user").(string); ok {
+		c.Locals(localsAdminUser, u)
+		return c.Next()
+	}
+	if u, p, ok := basicAuth(c); ok && checkAdminPassword(u, p) {
+		c.Locals(localsAdminUser, u)
+		return c.Next()
+	}
+	if c.Method() == fiber.MethodGet && c.Accepts(fiber.MIMETextHTML) == fiber.MIMETextHTML {
+		return c.Redirect("/admin/login?next="+c.OriginalURL(), http.StatusFound)
+	}
+	// WWW-Authenticate를 보내지 않음. 브라우저가 basic auth 창을 띄우고 계정을 기억하지 않도록
+	return c.SendStatus(http.StatusUnauthorized)
+}
+
+func (h *adminHandler) renderLogin(c *fiber.Ctx, status int, message string) error {
+	return c.Status(status).Render("admin/login", fiber.Map{
+		"Page":  &PageConfig{Title: "로그인 - " + siteOf(c).Title},
+		"Next":  safeNext(c.Query("next")),
+		"Error": message,
+	}, "layout/admin")
+}
+
+// GET /admin/login
+func (h *adminHandler) loginPage(c *fiber.Ctx) error {
+	return h.renderLogin(c, http.StatusOK, "")
+}
+
+// POST /admin/login
+func (h *adminHandler) login(c *fiber.Ctx) error {
+	if !checkAdminPassword(c.FormValue("user"), c.FormValue("password")) {
+		return h.renderLogin(c, http.StatusUnauthorized, "아이디 또는 비밀번호가 틀렸습니다")
+	}
+	sess, err := h.sessions.Get(c)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).SendString(err.Error())
+	}
+	// 로그인 전 세션 ID를 그대로 쓰지 않음 (session fixation 방지)
+	if err := sess.Regenerate(); err != nil {
+		return c.Status(http.StatusInternalServerError).SendString(err.Error())
+	}
+	sess.Set("user", c.FormValue("user"))
+	if err := sess.Save(); err != nil {
+		return c.Status(http.StatusInternalServerError).SendString(err.Error())
+	}
+	return c.Redirect(safeNext(c.FormValue("next")), http.StatusSeeOther)
+}
+
+// POST /admin/logout
+func (h *adminHandler) logout(c *fiber.Ctx) error {
+	sess, err := h.sessions.Get(c)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).SendString(err.Error())
+	}
+	if err := sess.Destroy(); err != nil {
+		return c.Status(http.StatusInternalServerError).SendString(err.Error())
+	}
+	return c.Redirect("/admin/login", http.StatusSeeOther)
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jeonghoikun/colagom.com/site"
+	"github.com/jeonghoikun/colagom.com/store"
+)
+
+// templateVersion: views 디렉토리의 파일 경로, 크기, 수정시간으로 만든 해시.
+// watchTemplates가 주기적으로 다시 계산
+var templateVersion atomic.Value
+
+func currentTemplateVersion() string {
+	v, _ := templateVersion.Load().(string)
+	return v
+}
+
+// watchTemplates: 템플릿 파일이 바뀌면 templateVersion을 갱신하고 onChange 호출
+func watchTemplates(dirs []string, interval time.Duration, onChange func()) {
+	for range time.Tick(interval) {
+		v, err := computeTemplateVersion(dirs...)
+		if err != nil {
+			log.Printf("server: template version: %s", err)
+			continue
+		}
+		if v != currentTemplateVersion() {
+			templateVersion.Store(v)
+			onChange()
+		}
+	}
+}
+
+func computeTemplateVersion(dirs ...string) (string, error) {
+	h := sha1.New()
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(h, "%s:%d:%d\n", path, info.Size(), info.ModTime().UnixNano())
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12], nil
+}
+
+// etag: 빌드 버전, 템플릿 버전, 카탈로그 구성, 전화번호 규칙, 후기 승인 상태, 페이지 수정일이
+// 모두 같을 때만 같은 값
+func etag(modified time.Time, reviewsVersion string) string {
+	return fmt.Sprintf(`W/"%s-%s-%s-%s-%s-%x"`, site.BuildVersion, currentTemplateVersion(),
+		store.Fingerprint(), store.ContactVersion(time.Now()), reviewsVersion, modified.Unix())
+}
+
+func etagMatches(header, tag string) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" || strings.TrimPrefix(t, "W/") == strings.TrimPrefix(tag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified: ETag, Last-Modified, Cache-Control 헤더를 설정하고
+// 클라이언트가 가진 페이지가 최신이면 304를 응답한 뒤 true 반환.
+// 핸들러는 true를 받으면 렌더링 없이 바로 return 해야 함
+func notModified(c *fiber.Ctx, modified time.Time) bool {
+	modified = modified.Truncate(time.Second)
+	tag := etag(modified, reviewsOf(c).Version())
+	c.Set(fiber.HeaderETag, tag)
+	c.Set(fiber.HeaderLastModified, modified.UTC().Format(http.TimeFormat))
+	c.Set(fiber.HeaderCacheControl, "public, max-age=0, must-revalidate")
+
+	if inm := c.Get(fiber.HeaderIfNoneMatch); inm != "" {
+		if !etagMatches(inm, tag) {
+			return false
+		}
+	} else if ims := c.Get(fiber.HeaderIfModifiedSince); ims != "" {
+		t, err := http.ParseTime(ims)
+		if err != nil || modified.After(t) {
+			return false
+		}
+	} else {
+		return false
+	}
+	dropCSP(c)
+	c.Status(http.StatusNotModified)
+	return true
+}
+
+// assetVersion: css 캐시 무효화용 쿼리 값. main.css가 바뀔 때마다 달라짐
+func assetVersion(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return site.BuildVersion
+	}
+	return fmt.Sprintf("%s-%x", site.BuildVersion, info.ModTime().Unix())
+}
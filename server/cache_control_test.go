@@ -0,0 +1,32 @@
+package server
+
+import "testing"
+
+func TestETagMatches(t *testing.T) {
+	const tag = `W/"dev-abc-1"`
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{`W/"dev-abc-1"`, true},
+		// If-None-Match는 weak 비교
+		{`"dev-abc-1"`, true},
+		{`W/"dev-abc-2"`, false},
+		{`"x", W/"dev-abc-1"`, true},
+		{`"x",W/"dev-abc-1" `, true},
+		{`"x", "y"`, false},
+		{`*`, true},
+		{` * `, true},
+		{`dev-abc-1`, false},
+		{`W/"dev-abc-1-extra"`, false},
+		{``, false},
+	}
+	for _, tt := range tests {
+		if got := etagMatches(tt.header, tag); got != tt.want {
+			t.Errorf("etagMatches(%q, %q) = %v, want %v", tt.header, tag, got, tt.want)
+		}
+	}
+	if !etagMatches(`W/"a"`, `"a"`) {
+		t.Error(`strong tag should match its weak form`)
+	}
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"net"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jeonghoikun/colagom.com/ratelimit"
+	"github.com/jeonghoikun/colagom.com/site"
+)
+
+// clientIPs: 요청한 클라이언트 IP. 연결한 IP가 신뢰하는 프록시(TrustedProxies)일 때만 프록시 헤더를 믿음
+type clientIPs struct {
+	header  string
+	trusted *ratelimit.BlockList
+}
+
+// newClientIPs: cfg가 nil이면 항상 연결한 IP
+func newClientIPs(cfg *site.RateLimit) (*clientIPs, error) {
+	ips := &clientIPs{trusted: &ratelimit.BlockList{}}
+	if cfg == nil || cfg.IPHeader == "" {
+		return ips, nil
+	}
+	trusted, err := ratelimit.ParseBlockList(cfg.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	ips.header, ips.trusted = cfg.IPHeader, trusted
+	return ips, nil
+}
+
+// behindProxy: 프록시 헤더를 설정했는지. 설정했으면 연결한 IP는 대부분 프록시
+func (r *clientIPs) behindProxy() bool { return r.header != "" }
+
+func (r *clientIPs) ip(c *fiber.Ctx) string {
+	if r.header == "" {
+		return c.IP()
+	}
+	return r.forwarded(c.IP(), c.Get(r.header))
+}
+
+// forwarded: peer가 신뢰하는 프록시면 헤더 값에서 오른쪽부터 신뢰하는 프록시를 건너뛴 첫 IP.
+// 왼쪽 값은 클라이언트가 마음대로 넣을 수 있으므로 사용하지 않음.
+// ex) X-Forwarded-For: 위조, 클라이언트, 프록시1 => 클라이언트
+func (r *clientIPs) forwarded(peer, value string) string {
+	if !r.trusted.Contains(peer) {
+		return peer
+	}
+	ip := peer
+	parts := strings.Split(value, ",")
+	for i := len(parts) - 1; i >= 0; i-- {
+		v := strings.TrimSpace(parts[i])
+		if net.ParseIP(v) == nil {
+			break
+		}
+		ip = v
+		if !r.trusted.Contains(v) {
+			break
+		}
+	}
+	return ip
+}
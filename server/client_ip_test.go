@@ -0,0 +1,49 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/jeonghoikun/colagom.com/site"
+)
+
+func TestClientIPsForwarded(t *testing.T) {
+	ips, err := newClientIPs(&site.RateLimit{
+		IPHeader:       "X-Forwarded-For",
+		TrustedProxies: []string{"127.0.0.1", "10.0.0.0/8"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name, peer, header, want string
+	}{
+		{"untrusted peer ignores header", "203.0.113.9", "1.2.3.4", "203.0.113.9"},
+		{"single hop", "127.0.0.1", "198.51.100.1", "198.51.100.1"},
+		{"forged left value is skipped", "127.0.0.1", "1.2.3.4, 198.51.100.1", "198.51.100.1"},
+		{"trusted hops are skipped", "127.0.0.1", "1.2.3.4, 198.51.100.1, 10.0.0.5", "198.51.100.1"},
+		{"all trusted", "127.0.0.1", "10.0.0.7, 10.0.0.5", "10.0.0.7"},
+		{"empty header", "127.0.0.1", "", "127.0.0.1"},
+		{"garbage stops the walk", "127.0.0.1", "198.51.100.1, nope", "127.0.0.1"},
+		{"ipv6 client", "10.1.2.3", "2001:db8::1", "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		if got := ips.forwarded(tt.peer, tt.header); got != tt.want {
+			t.Errorf("%s: forwarded(%q, %q) = %q, want %q", tt.name, tt.peer, tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestNewClientIPsWithoutHeader(t *testing.T) {
+	for _, cfg := range []*site.RateLimit{nil, {TrustedProxies: []string{"127.0.0.1"}}} {
+		ips, err := newClientIPs(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ips.behindProxy() {
+			t.Errorf("behindProxy() = true for %+v", cfg)
+		}
+	}
+	if _, err := newClientIPs(&site.RateLimit{IPHeader: "X-Real-IP", TrustedProxies: []string{"proxy"}}); err == nil {
+		t.Error("invalid TrustedProxies should fail")
+	}
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jeonghoikun/colagom.com/site"
+	"github.com/jeonghoikun/colagom.com/store"
+)
+
+// 404 페이지에 보여줄 추천 링크 수
+const suggestionCount = 5
+
+type Suggestion struct {
+	Title string
+	Path  string
+}
+
+func storeSuggestions(list []*store.Store) []*Suggestion {
+	suggestions := []*Suggestion{}
+	for _, s := range list {
+		suggestions = append(suggestions, &Suggestion{
+			Title: fmt.Sprintf("%s %s %s", s.Location.Dong, s.Title, s.Type),
+			Path: fmt.Sprintf("/store/%s/%s/%s/%s/%s",
+				s.Location.Do, s.Location.Si, s.Location.Dong, s.Type, s.Title),
+		})
+	}
+	return suggestions
+}
+
+func categorySuggestions(list []*store.Category) []*Suggestion {
+	suggestions := []*Suggestion{}
+	for _, c := range list {
+		suggestions = append(suggestions, &Suggestion{
+			Title: fmt.Sprintf("%s %s 업소 목록", c.Stores[0].Location.SiShort(), c.Name),
+			Path:  c.Path(),
+		})
+	}
+	return suggestions
+}
+
+// renderError: 사이트 레이아웃으로 에러 페이지 응답
+func renderError(c *fiber.Ctx, status int, message string, suggestions []*Suggestion) error {
+	cfg := siteOf(c)
+	title := fmt.Sprintf("%d %s", status, http.StatusText(status))
+	phoneNumber := sitePhoneNumber(cfg)
+	m := fiber.Map{
+		"Page": &PageConfig{
+			Path:        pagePath(c),
+			Author:      store.SiteAuthor(cfg),
+			Title:       fmt.Sprintf("%s - %s", message, cfg.Title),
+			Description: message,
+			PhoneNumber: phoneNumber,
+		},
+		"Profile": map[string]string{
+			"PhoneNumber": phoneNumber,
+			"CallPath":    callPath(siteCallKey, c.Path()),
+		},
+		"Error": fiber.Map{
+			"Status":      status,
+			"Title":       title,
+			"Message":     message,
+			"Suggestions": suggestions,
+			// RequestID: 문의할 때 로그를 찾을 수 있도록 보여줌
+			"RequestID": requestIDOf(c),
+		},
+	}
+	return c.Status(status).Render("error/index", m, "layout/error")
+}
+
+// notFound: 어떤 route에도 맞지 않는 요청. 마지막 경로를 상호로 보고 추천
+func notFound(c *fiber.Ctx) error {
+	last, err := url.PathUnescape(path.Base(c.Path()))
+	if err != nil {
+		last = ""
+	}
+	suggestions := storeSuggestions(catalogOf(c).SuggestStores("", "", last, suggestionCount))
+	return renderError(c, http.StatusNotFound, "페이지를 찾을 수 없습니다", suggestions)
+}
+
+// errorHandler: 핸들러가 반환한 에러와 panic 응답(fiber.Config.ErrorHandler).
+// fiber.Error는 그 상태 코드, 나머지는 500. 500 페이지를 렌더링하지 못하면 text로 응답
+func errorHandler(c *fiber.Ctx, err error) error {
+	status := http.StatusInternalServerError
+	message := "일시적인 오류가 발생했습니다. 잠시 후 다시 시도해 주세요"
+	var fe *fiber.Error
+	if errors.As(err, &fe) {
+		status = fe.Code
+		if status < http.StatusInternalServerError {
+			message = fe.Message
+		}
+	}
+	c.Response().ResetBody()
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	if site.Config.Dev && status >= http.StatusInternalServerError {
+		return renderDevError(c, status, err)
+	}
+	if rerr := renderError(c, status, message, nil); rerr != nil {
+		c.Response().ResetBody()
+		c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+		return c.Status(status).SendString(http.StatusText(status))
+	}
+	return nil
+}
+
+// templateErrorLine: ex) template: store/서울/강남구/역삼동/쩜오/에이원:12:5: executing ...
+var templateErrorLine = regexp.MustCompile(`template: ([^:]+):(\d+)`)
+
+// devSourceContext: 에러 난 줄 앞뒤로 보여줄 줄 수
+const devSourceContext = 3
+
+// templateSource: 에러 메세지의 템플릿 파일에서 에러 난 줄 주변. 찾지 못하면 빈 값
+func templateSource(cfg *site.Site, message string) string {
+	m := templateErrorLine.FindStringSubmatch(message)
+	if m == nil {
+		return ""
+	}
+	line, _ := strconv.Atoi(m[2])
+	f, err := newOverlayFS(viewsDirs(cfg)...).Open("/" + m[1] + ".html")
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s.html\n", m[1])
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		if n < line-devSourceContext || n > line+devSourceContext {
+			continue
+		}
+		mark := "  "
+		if n == line {
+			mark = "> "
+		}
+		fmt.Fprintf(&b, "%s%4d | %s\n", mark, n, scanner.Text())
+	}
+	return b.String()
+}
+
+// renderDevError: 개발 모드 500 페이지. 템플릿이 깨져도 보이도록 템플릿 없이 만듦
+func renderDevError(c *fiber.Ctx, status int, err error) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html lang=\"ko\">\n<head><meta charset=\"utf-8\"><title>%d %s</title></head>\n<body>\n",
+		status, http.StatusText(status))
+	fmt.Fprintf(&b, "<h1>%d %s</h1>\n<p>%s %s</p>\n<p>요청 ID: %s</p>\n",
+		status, http.StatusText(status), c.Method(), html.EscapeString(c.OriginalURL()), html.EscapeString(requestIDOf(c)))
+	fmt.Fprintf(&b, "<h2>에러</h2>\n<pre>%s</pre>\n", html.EscapeString(err.Error()))
+	if source := templateSource(siteOf(c), err.Error()); source != "" {
+		fmt.Fprintf(&b, "<h2>템플릿</h2>\n<pre>%s</pre>\n", html.EscapeString(source))
+	}
+	var pe *panicError
+	if errors.As(err, &pe) {
+		fmt.Fprintf(&b, "<h2>Stack</h2>\n<pre>%s</pre>\n", html.EscapeString(string(pe.stack)))
+	}
+	b.WriteString("</body>\n</html>\n")
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.Status(status).SendString(b.String())
+}
@@ -0,0 +1,236 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/session"
+	"github.com/jeonghoikun/colagom.com/analytics"
+	"github.com/jeonghoikun/colagom.com/calllog"
+	"github.com/jeonghoikun/colagom.com/review"
+	"github.com/jeonghoikun/colagom.com/site"
+	"github.com/jeonghoikun/colagom.com/store"
+)
+
+type adminHandler struct {
+	cache     *renderCache
+	calls     *calllog.Log
+	pageViews *analytics.Recorder
+	reviews   *review.DB
+	sessions  *session.Store
+}
+
+// GET /admin/cache
+func (h *adminHandler) cacheStats(c *fiber.Ctx) error {
+	return c.Status(http.StatusOK).JSON(h.cache.Stats())
+}
+
+// POST /admin/cache/purge
+func (h *adminHandler) cachePurge(c *fiber.Ctx) error {
+	h.cache.Purge()
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// POST /admin/catalog/reload
+func (h *adminHandler) catalogReload(c *fiber.Ctx) error {
+	ch, err := store.Reload()
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).SendString(err.Error())
+	}
+	changed := []string{}
+	for _, s := range ch.Stores {
+		changed = append(changed, s.Key())
+	}
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"structural": ch.Structural,
+		"changed":    changed,
+	})
+}
+
+func contactRuleError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, store.ErrContactRuleNotFound) {
+		return c.Status(http.StatusNotFound).SendString(err.Error())
+	}
+	return c.Status(http.StatusBadRequest).SendString(err.Error())
+}
+
+// GET /admin/contact/rules
+func (*adminHandler) contactRules(c *fiber.Ctx) error {
+	return c.Status(http.StatusOK).JSON(store.ListContactRules())
+}
+
+// POST /admin/contact/rules
+func (*adminHandler) contactRuleAdd(c *fiber.Ctx) error {
+	r := &store.ContactRule{}
+	if err := c.BodyParser(r); err != nil {
+		return c.Status(http.StatusBadRequest).SendString(err.Error())
+	}
+	if r.ID == "" {
+		r.ID = store.NewContactRuleID()
+	}
+	if err := store.AddContactRule(r, adminUser(c)); err != nil {
+		return contactRuleError(c, err)
+	}
+	return c.Status(http.StatusCreated).JSON(r)
+}
+
+// PUT /admin/contact/rules/:id
+func (*adminHandler) contactRuleUpdate(c *fiber.Ctx) error {
+	id, err := url.QueryUnescape(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).SendString(err.Error())
+	}
+	r := &store.ContactRule{}
+	if err := c.BodyParser(r); err != nil {
+		return c.Status(http.StatusBadRequest).SendString(err.Error())
+	}
+	r.ID = id
+	if err := store.UpdateContactRule(r, adminUser(c)); err != nil {
+		return contactRuleError(c, err)
+	}
+	return c.Status(http.StatusOK).JSON(r)
+}
+
+// DELETE /admin/contact/rules/:id
+func (*adminHandler) contactRuleDelete(c *fiber.Ctx) error {
+	id, err := url.QueryUnescape(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).SendString(err.Error())
+	}
+	if err := store.DeleteContactRule(id, adminUser(c)); err != nil {
+		return contactRuleError(c, err)
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// GET /admin/contact/audit
+func (*adminHandler) contactAudit(c *fiber.Ctx) error {
+	list, err := store.ListContactAudit()
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).SendString(err.Error())
+	}
+	return c.Status(http.StatusOK).JSON(list)
+}
+
+// dateRange: ?from=2024-06-01&to=2024-06-30 기간. 입력하지 않으면 최근 30일.
+// to는 그 날짜 하루 전체를 포함
+func dateRange(c *fiber.Ctx) (from, to time.Time, err error) {
+	now := time.Now()
+	to = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	from = to.AddDate(0, 0, -29)
+	if v := c.Query("from"); v != "" {
+		if from, err = time.ParseInLocation("2006-01-02", v, time.Local); err != nil {
+			return
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		if to, err = time.ParseInLocation("2006-01-02", v, time.Local); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// GET /admin/calls?from=2024-06-01&to=2024-06-30
+func (h *adminHandler) callReport(c *fiber.Ctx) error {
+	from, to, err := dateRange(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).SendString(err.Error())
+	}
+	report, err := h.calls.Report(siteOf(c).Domain, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).SendString(err.Error())
+	}
+	return c.Status(http.StatusOK).JSON(report)
+}
+
+func (h *adminHandler) pageViewBuckets(c *fiber.Ctx) (from, to time.Time, buckets []*analytics.Bucket, err error) {
+	if h.pageViews == nil {
+		return from, to, nil, fiber.NewError(http.StatusServiceUnavailable, "조회수 기록이 꺼져 있습니다")
+	}
+	if from, to, err = dateRange(c); err != nil {
+		return from, to, nil, fiber.NewError(http.StatusBadRequest, err.Error())
+	}
+	buckets, err = h.pageViews.Buckets(siteOf(c).Domain, from, to.AddDate(0, 0, 1))
+	return
+}
+
+// GET /admin/analytics?from=2024-06-01&to=2024-06-30
+func (h *adminHandler) analytics(c *fiber.Ctx) error {
+	from, to, buckets, err := h.pageViewBuckets(c)
+	if err != nil {
+		return err
+	}
+	routes := analytics.Summarize(buckets)
+	var human, crawls uint64
+	for _, r := range routes {
+		human += r.Human
+		crawls += r.Crawls
+	}
+	m := fiber.Map{
+		"Page": &PageConfig{Title: "페이지 조회수 - " + siteOf(c).Title},
+		"Analytics": fiber.Map{
+			"From":      from,
+			"To":        to,
+			"Human":     human,
+			"Crawls":    crawls,
+			"Routes":    routes,
+			"Referrers": analytics.TopReferrers(buckets, 20),
+		},
+	}
+	return c.Status(http.StatusOK).Render("admin/analytics", m, "layout/admin")
+}
+
+// GET /admin/analytics.json?from=2024-06-01&to=2024-06-30
+// 시간별 조회수 전체
+func (h *adminHandler) analyticsExport(c *fiber.Ctx) error {
+	_, _, buckets, err := h.pageViewBuckets(c)
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusOK).JSON(buckets)
+}
+
+// BaseURL = /admin
+func handleAdmin(r fiber.Router, cfg *site.Site, cache *renderCache, calls *calllog.Log, pageViews *analytics.Recorder, reviews *review.DB) {
+	if site.Config.AdminPassword == "" {
+		return
+	}
+	h := &adminHandler{
+		cache:     cache,
+		calls:     calls,
+		pageViews: pageViews,
+		reviews:   reviews,
+		sessions:  newAdminSessions(cfg),
+	}
+	r.Get("/login", h.loginPage)
+	r.Post("/login", h.login)
+	r.Use(h.requireLogin)
+	r.Post("/logout", h.logout)
+	r.Get("/", h.storeList)
+	r.Get("/stores/new", h.storeNew)
+	r.Post("/stores", h.storeCreate)
+	r.Get("/stores/:key", h.storeEdit)
+	r.Post("/stores/:key", h.storeUpdate)
+	r.Post("/stores/:key/close", h.storeClose)
+	r.Post("/stores/:key/reopen", h.storeReopen)
+	r.Post("/stores/:key/images", h.storeImages)
+	r.Post("/stores/:key/body", h.storeBody)
+	r.Get("/cache", h.cacheStats)
+	r.Post("/cache/purge", h.cachePurge)
+	r.Post("/catalog/reload", h.catalogReload)
+	r.Get("/contact/rules", h.contactRules)
+	r.Post("/contact/rules", h.contactRuleAdd)
+	r.Put("/contact/rules/:id", h.contactRuleUpdate)
+	r.Delete("/contact/rules/:id", h.contactRuleDelete)
+	r.Get("/contact/audit", h.contactAudit)
+	r.Get("/calls", h.callReport)
+	r.Get("/analytics", h.analytics)
+	r.Get("/analytics.json", h.analyticsExport)
+	r.Get("/reviews", h.reviewList)
+	r.Post("/reviews/:id/approve", h.reviewApprove)
+	r.Post("/reviews/:id/reject", h.reviewReject)
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jeonghoikun/colagom.com/review"
+	"github.com/jeonghoikun/colagom.com/store"
+)
+
+// adminReview: 관리자 화면에 보여줄 후기와 가게
+type adminReview struct {
+	*review.Review
+	Store *store.Store
+}
+
+func (h *adminHandler) reviewsEnabled() error {
+	if h.reviews == nil {
+		return fiber.NewError(http.StatusServiceUnavailable, "후기 기능이 꺼져 있습니다")
+	}
+	return nil
+}
+
+// GET /admin/reviews?status=pending
+// status를 입력하지 않으면 검사 대기중인 후기
+func (h *adminHandler) reviewList(c *fiber.Ctx) error {
+	if err := h.reviewsEnabled(); err != nil {
+		return err
+	}
+	status := review.Status(c.Query("status", string(review.Pending)))
+	list, err := h.reviews.List(status)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).SendString(err.Error())
+	}
+	reviews := []*adminReview{}
+	for _, r := range list {
+		s, _ := store.FindStore(r.StoreKey)
+		reviews = append(reviews, &adminReview{Review: r, Store: s})
+	}
+	return c.Status(http.StatusOK).Render("admin/reviews", fiber.Map{
+		"Page":     &PageConfig{Title: "후기 관리 - " + siteOf(c).Title},
+		"Status":   status,
+		"Statuses": []review.Status{review.Pending, review.Approved, review.Rejected},
+		"Reviews":  reviews,
+	}, "layout/admin")
+}
+
+func (h *adminHandler) moderate(c *fiber.Ctx, status review.Status) error {
+	if err := h.reviewsEnabled(); err != nil {
+		return err
+	}
+	id, err := url.QueryUnescape(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).SendString(err.Error())
+	}
+	if _, err := h.reviews.Moderate(id, status, adminUser(c)); err != nil {
+		if errors.Is(err, review.ErrNotFound) {
+			return c.Status(http.StatusNotFound).SendString(err.Error())
+		}
+		return c.Status(http.StatusInternalServerError).SendString(err.Error())
+	}
+	// 처리하던 목록으로 돌아감
+	back := review.Status(c.FormValue("status", string(review.Pending)))
+	return c.Redirect("/admin/reviews?status="+url.QueryEscape(string(back)), http.StatusSeeOther)
+}
+
+// POST /admin/reviews/:id/approve
+func (h *adminHandler) reviewApprove(c *fiber.Ctx) error { return h.moderate(c, review.Approved) }
+
+// POST /admin/reviews/:id/reject
+func (h *adminHandler) reviewReject(c *fiber.Ctx) error { return h.moderate(c, review.Rejected) }
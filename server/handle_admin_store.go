@@ -0,0 +1,287 @@
+package server
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jeonghoikun/colagom.com/store"
+)
+
+func adminStorePath(key string) string { return "/admin/stores/" + url.PathEscape(key) }
+
+// adminStore: :key 파라미터의 가게
+func adminStore(c *fiber.Ctx) (*store.Store, error) {
+	key, err := url.QueryUnescape(c.Params("key"))
+	if err != nil {
+		return nil, fiber.NewError(http.StatusBadRequest, err.Error())
+	}
+	s, has := store.FindStore(key)
+	if !has {
+		return nil, fiber.NewError(http.StatusNotFound, store.ErrStoreNotFound.Error())
+	}
+	return s, nil
+}
+
+func formInt(c *fiber.Ctx, name string) (int, error) {
+	v := strings.ReplaceAll(strings.TrimSpace(c.FormValue(name)), ",", "")
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: 숫자가 아닙니다: %q", name, v)
+	}
+	return n, nil
+}
+
+func formFloat(c *fiber.Ctx, name string) (float64, error) {
+	v := strings.TrimSpace(c.FormValue(name))
+	if v == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: 숫자가 아닙니다: %q", name, v)
+	}
+	return f, nil
+}
+
+func formTimeType(c *fiber.Ctx, part string) *store.TimeType {
+	return &store.TimeType{
+		Has:    c.FormValue(part+"Has") != "",
+		Open:   strings.TrimSpace(c.FormValue(part + "Open")),
+		Closed: strings.TrimSpace(c.FormValue(part + "Closed")),
+	}
+}
+
+// storeFromForm: 가게 수정 form. Active는 폐업, 영업 재개 버튼으로만 바꿈
+func storeFromForm(c *fiber.Ctx, active *store.Active) (*store.Store, error) {
+	s := &store.Store{
+		Location: &store.Location{
+			Do:           strings.TrimSpace(c.FormValue("do")),
+			Si:           strings.TrimSpace(c.FormValue("si")),
+			Dong:         strings.TrimSpace(c.FormValue("dong")),
+			Address:      strings.TrimSpace(c.FormValue("address")),
+			RoadAddress:  strings.TrimSpace(c.FormValue("roadAddress")),
+			BuildingName: strings.TrimSpace(c.FormValue("buildingName")),
+			GoogleMapSrc: strings.TrimSpace(c.FormValue("googleMapSrc")),
+		},
+		Type:        c.FormValue("type"),
+		Title:       strings.TrimSpace(c.FormValue("title")),
+		Description: strings.TrimSpace(c.FormValue("description")),
+		Author:      c.FormValue("author"),
+		Active:      active,
+		Hour: &store.Hour{
+			Part1: formTimeType(c, "part1"),
+			Part2: formTimeType(c, "part2"),
+		},
+		Menu: &store.Menu{},
+	}
+	for _, f := range []struct {
+		name string
+		v    *int
+	}{
+		{"part1Whisky", &s.Menu.Part1Whisky},
+		{"part2Whisky", &s.Menu.Part2Whisky},
+		{"tc", &s.Menu.TC},
+		{"rt", &s.Menu.RT},
+	} {
+		n, err := formInt(c, f.name)
+		if err != nil {
+			return s, err
+		}
+		*f.v = n
+	}
+	// 좌표는 화면에 보이지 않는 hidden 필드. 없으면 저장할 때 GoogleMapSrc에서 찾음
+	for _, f := range []struct {
+		name string
+		v    *float64
+	}{
+		{"latitude", &s.Location.Latitude},
+		{"longitude", &s.Location.Longitude},
+	} {
+		n, err := formFloat(c, f.name)
+		if err != nil {
+			return s, err
+		}
+		*f.v = n
+	}
+	return s, nil
+}
+
+func (h *adminHandler) renderStoreForm(c *fiber.Ctx, status int, key string, s *store.Store, message string) error {
+	m := fiber.Map{
+		"Page":    &PageConfig{Title: "가게 관리 - " + siteOf(c).Title},
+		"Key":     key,
+		"Store":   s,
+		"Types":   store.StoreTypes,
+		"Authors": store.ListAuthors(),
+		"Error":   message,
+		"Saved":   c.Query("saved") != "",
+		"Action":  "/admin/stores",
+		"Gallery": []*store.Image{},
+	}
+	if key != "" {
+		m["Action"] = adminStorePath(key)
+		if current, has := store.FindStore(key); has {
+			m["Gallery"] = current.Gallery
+			m["StorePath"] = current.Path()
+			body, err := current.BodySource()
+			if err != nil {
+				return c.Status(http.StatusInternalServerError).SendString(err.Error())
+			}
+			// 본문 저장에 실패했으면 입력한 내용을 그대로 보여줌
+			if v := c.FormValue("body"); c.Method() == fiber.MethodPost && v != "" {
+				body = v
+			}
+			m["Body"] = body
+		}
+	}
+	return c.Status(status).Render("admin/store", m, "layout/admin")
+}
+
+// GET /admin
+func (*adminHandler) storeList(c *fiber.Ctx) error {
+	list := append([]*store.Store{}, store.ListAllStores()...)
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Key() < list[j].Key() })
+	return c.Status(http.StatusOK).Render("admin/index", fiber.Map{
+		"Page":   &PageConfig{Title: "관리자 - " + siteOf(c).Title},
+		"User":   adminUser(c),
+		"Stores": list,
+	}, "layout/admin")
+}
+
+// GET /admin/stores/new
+func (h *adminHandler) storeNew(c *fiber.Ctx) error {
+	s := &store.Store{
+		Location: &store.Location{},
+		Type:     store.StoreTypes[0],
+		Active:   &store.Active{},
+		Hour:     &store.Hour{Part1: &store.TimeType{}, Part2: &store.TimeType{}},
+		Menu:     &store.Menu{},
+	}
+	if cfg := siteOf(c); len(cfg.Regions) > 0 {
+		s.Location.Do, s.Location.Si = cfg.Regions[0].Do, cfg.Regions[0].Si
+	}
+	return h.renderStoreForm(c, http.StatusOK, "", s, "")
+}
+
+// POST /admin/stores
+func (h *adminHandler) storeCreate(c *fiber.Ctx) error {
+	s, err := storeFromForm(c, &store.Active{})
+	if err != nil {
+		return h.renderStoreForm(c, http.StatusBadRequest, "", s, err.Error())
+	}
+	if _, err := store.Save("", s); err != nil {
+		return h.renderStoreForm(c, http.StatusBadRequest, "", s, err.Error())
+	}
+	return c.Redirect(adminStorePath(s.Key())+"?saved=1", http.StatusSeeOther)
+}
+
+// GET /admin/stores/:key
+func (h *adminHandler) storeEdit(c *fiber.Ctx) error {
+	s, err := adminStore(c)
+	if err != nil {
+		return err
+	}
+	return h.renderStoreForm(c, http.StatusOK, s.Key(), s, "")
+}
+
+// POST /admin/stores/:key
+func (h *adminHandler) storeUpdate(c *fiber.Ctx) error {
+	old, err := adminStore(c)
+	if err != nil {
+		return err
+	}
+	active := *old.Active
+	s, err := storeFromForm(c, &active)
+	if err != nil {
+		return h.renderStoreForm(c, http.StatusBadRequest, old.Key(), s, err.Error())
+	}
+	// 지도를 바꿨으면 이전 지도의 좌표 대신 새 지도에서 찾음
+	if s.Location.GoogleMapSrc != old.Location.GoogleMapSrc {
+		s.Location.Latitude, s.Location.Longitude = 0, 0
+	}
+	if _, err := store.Save(old.Key(), s); err != nil {
+		return h.renderStoreForm(c, http.StatusBadRequest, old.Key(), s, err.Error())
+	}
+	return c.Redirect(adminStorePath(s.Key())+"?saved=1", http.StatusSeeOther)
+}
+
+func (h *adminHandler) setClosed(c *fiber.Ctx, closed bool) error {
+	s, err := adminStore(c)
+	if err != nil {
+		return err
+	}
+	if _, err := store.SetClosed(s.Key(), closed, strings.TrimSpace(c.FormValue("reason"))); err != nil {
+		return h.renderStoreForm(c, http.StatusBadRequest, s.Key(), s, err.Error())
+	}
+	return c.Redirect(adminStorePath(s.Key())+"?saved=1", http.StatusSeeOther)
+}
+
+// POST /admin/stores/:key/close
+func (h *adminHandler) storeClose(c *fiber.Ctx) error { return h.setClosed(c, true) }
+
+// POST /admin/stores/:key/reopen
+func (h *adminHandler) storeReopen(c *fiber.Ctx) error { return h.setClosed(c, false) }
+
+// POST /admin/stores/:key/images
+// 같은 이름의 파일은 덮어씀. thumbnail.png는 목록, 공유 이미지에 쓰는 대표 이미지
+func (h *adminHandler) storeImages(c *fiber.Ctx) error {
+	s, err := adminStore(c)
+	if err != nil {
+		return err
+	}
+	form, err := c.MultipartForm()
+	if err != nil {
+		return h.renderStoreForm(c, http.StatusBadRequest, s.Key(), s, err.Error())
+	}
+	files := form.File["images"]
+	if len(files) == 0 {
+		return h.renderStoreForm(c, http.StatusBadRequest, s.Key(), s, "업로드할 이미지를 선택하세요")
+	}
+	for _, f := range files {
+		name := filepath.Base(f.Filename)
+		if strings.HasPrefix(name, ".") || !store.IsImageFile(name) {
+			return h.renderStoreForm(c, http.StatusBadRequest, s.Key(), s,
+				fmt.Sprintf("%s: 이미지 파일(png, jpg, webp, gif)만 올릴 수 있습니다", f.Filename))
+		}
+		if err := c.SaveFile(f, filepath.Join(s.ImageDir(), name)); err != nil {
+			return c.Status(http.StatusInternalServerError).SendString(err.Error())
+		}
+	}
+	// 갤러리 다시 만들기
+	if _, err := store.Reload(); err != nil {
+		return h.renderStoreForm(c, http.StatusBadRequest, s.Key(), s, err.Error())
+	}
+	return c.Redirect(adminStorePath(s.Key())+"?saved=1", http.StatusSeeOther)
+}
+
+// POST /admin/stores/:key/body
+func (h *adminHandler) storeBody(c *fiber.Ctx) error {
+	s, err := adminStore(c)
+	if err != nil {
+		return err
+	}
+	body := c.FormValue("body")
+	// 렌더링할 때 깨지지 않도록 저장 전에 템플릿 문법 검사. Markdown은 저장할 때 카탈로그 검사
+	if !s.IsMarkdown() {
+		if _, err := template.New("body").Funcs(engine(siteOf(c), reviewsOf(c)).FuncMap()).Parse(body); err != nil {
+			return h.renderStoreForm(c, http.StatusBadRequest, s.Key(), s, err.Error())
+		}
+	}
+	if err := s.SaveBodySource(body); err != nil {
+		if s.IsMarkdown() {
+			return h.renderStoreForm(c, http.StatusBadRequest, s.Key(), s, err.Error())
+		}
+		return c.Status(http.StatusInternalServerError).SendString(err.Error())
+	}
+	return c.Redirect(adminStorePath(s.Key())+"?saved=1", http.StatusSeeOther)
+}
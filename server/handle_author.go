@@ -0,0 +1,69 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jeonghoikun/colagom.com/store"
+)
+
+// authorCacheTag: 작성자 페이지는 가게가 바뀌면 모두 제거
+const authorCacheTag = "author"
+
+type authorHandler struct{}
+
+// GET /author/:slug
+func (*authorHandler) page(c *fiber.Ctx) error {
+	cfg := siteOf(c)
+	slug, err := url.QueryUnescape(c.Params("slug"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).SendString(err.Error())
+	}
+	author, has := store.GetAuthor(slug)
+	if !has {
+		return renderError(c, http.StatusNotFound, "작성자를 찾을 수 없습니다", nil)
+	}
+	listStores := catalogOf(c).ListStoresByAuthor(cfg, author)
+	modified := store.LatestModified(listStores)
+	if cfg.DateModified.After(modified) {
+		modified = cfg.DateModified
+	}
+	if notModified(c, modified) {
+		return nil
+	}
+	cacheable(c, authorCacheTag)
+	sort.Slice(listStores, func(i, j int) bool {
+		return listStores[i].DatePublished.UnixNano() > listStores[j].DatePublished.UnixNano()
+	})
+	phoneNumber := sitePhoneNumber(cfg)
+	m := fiber.Map{
+		"Page": &PageConfig{
+			Path:          pagePath(c),
+			Author:        author,
+			Title:         fmt.Sprintf("작성자 %s - %s", author.Name, cfg.Title),
+			Description:   author.Bio,
+			Keywords:      author.Name,
+			PhoneNumber:   phoneNumber,
+			DatePublished: cfg.DatePublished,
+			DateModified:  modified,
+			ThumbnailPath: author.PhotoPath,
+			OGImagePath:   author.PhotoPath,
+		},
+		"Profile": map[string]string{
+			"PhoneNumber": phoneNumber,
+			"CallPath":    callPath(siteCallKey, c.Path()),
+		},
+		"Author": author,
+		"Stores": listStores,
+	}
+	return c.Status(http.StatusOK).Render("author/index", m, "layout/author")
+}
+
+// BaseURL = /author
+func handleAuthor(r fiber.Router) {
+	h := &authorHandler{}
+	r.Get("/:slug", h.page)
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jeonghoikun/colagom.com/analytics"
+	"github.com/jeonghoikun/colagom.com/calllog"
+	"github.com/jeonghoikun/colagom.com/store"
+)
+
+// siteCallKey: 가게, 카테고리 페이지가 아닌 곳의 전화 연결 키
+const siteCallKey = "site"
+
+// callPath: 전화 연결 버튼 링크. page는 버튼이 있는 페이지 경로
+func callPath(key, page string) string {
+	return "/call/" + url.PathEscape(key) + "?page=" + url.QueryEscape(page)
+}
+
+func categoryCallKey(do, si, storeType string) string {
+	return strings.Join([]string{do, si, storeType}, ":")
+}
+
+type callHandler struct {
+	calls *calllog.Log
+}
+
+// utm: /call 요청의 utm_* 값. 없으면 버튼이 있던 페이지(Referer)의 값
+func utm(c *fiber.Ctx, referrer *url.URL, name string) string {
+	if v := c.Query(name); v != "" {
+		return v
+	}
+	if referrer != nil {
+		return referrer.Query().Get(name)
+	}
+	return ""
+}
+
+// GET /call/:storeKey
+func (h *callHandler) call(c *fiber.Ctx) error {
+	cfg := siteOf(c)
+	catalog := catalogOf(c)
+	key, err := url.QueryUnescape(c.Params("storeKey"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).SendString(err.Error())
+	}
+	now := time.Now()
+	click := &calllog.Click{Time: now, Site: cfg.Domain, StoreKey: key}
+	var phoneNumber string
+	switch parts := strings.Split(key, ":"); {
+	case key == siteCallKey:
+		phoneNumber = sitePhoneNumber(cfg)
+	case len(parts) == 3:
+		if len(catalog.ListStoresByDoSiAndStoreType(parts[0], parts[1], parts[2])) == 0 {
+			return renderError(c, http.StatusNotFound, "카테고리를 찾을 수 없습니다", nil)
+		}
+		click.Type = parts[2]
+		phoneNumber = store.ResolvePhoneNumber(&store.ContactTarget{
+			Do: parts[0], Si: parts[1], Type: parts[2],
+		}, now, cfg.PhoneNumber)
+	case len(parts) == 5:
+		s, has := catalog.Get(parts[0], parts[1], parts[2], parts[3], parts[4])
+		if !has {
+			return renderError(c, http.StatusNotFound, "가게를 찾을 수 없습니다", nil)
+		}
+		logStoreKey(c, s.Key())
+		click.Type = s.Type
+		phoneNumber = s.PhoneNumber(now, cfg.PhoneNumber)
+	default:
+		return renderError(c, http.StatusNotFound, "가게를 찾을 수 없습니다", nil)
+	}
+
+	click.Referrer = c.Get(fiber.HeaderReferer)
+	referrer, _ := url.Parse(click.Referrer)
+	click.Page = c.Query("page")
+	if click.Page == "" && referrer != nil {
+		click.Page = referrer.Path
+	}
+	click.UTMSource = utm(c, referrer, "utm_source")
+	click.UTMMedium = utm(c, referrer, "utm_medium")
+	click.UTMCampaign = utm(c, referrer, "utm_campaign")
+	click.UTMTerm = utm(c, referrer, "utm_term")
+	click.UTMContent = utm(c, referrer, "utm_content")
+	if agent := analytics.Agent(c.Get(fiber.HeaderUserAgent)); agent != analytics.Human {
+		click.Bot = agent
+	}
+	// 기록에 실패해도 전화 연결은 해야 함
+	if err := h.calls.Append(click); err != nil {
+		loggerOf(c).Error("call log", "error", err)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set("X-Robots-Tag", "noindex, nofollow")
+	return c.Redirect("tel:"+phoneNumber, http.StatusFound)
+}
+
+// BaseURL = /call
+func handleCall(r fiber.Router, calls *calllog.Log) {
+	h := &callHandler{calls: calls}
+	r.Get("/:storeKey", h.call)
+}
@@ -6,9 +6,10 @@ import (
 	"net/url"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/jeonghoikun/colagom.com/site"
+	"github.com/jeonghoikun/colagom.com/review"
 	"github.com/jeonghoikun/colagom.com/store"
 )
 
@@ -16,6 +17,8 @@ type categoryHandler struct{}
 
 // GET /category/:do/:si/:storeType
 func (*categoryHandler) listPage(c *fiber.Ctx) error {
+	cfg := siteOf(c)
+	catalog := catalogOf(c)
 	do, err := url.QueryUnescape(c.Params("do"))
 	if err != nil {
 		return c.Status(http.StatusBadRequest).SendString(err.Error())
@@ -28,43 +31,75 @@ func (*categoryHandler) listPage(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(http.StatusBadRequest).SendString(err.Error())
 	}
-	listStores := store.ListStoresByDoSiAndStoreType(do, si, storeType)
+	listStores := catalog.ListStoresByDoSiAndStoreType(do, si, storeType)
 	if len(listStores) == 0 {
-		return c.Status(http.StatusNotFound).SendString("카테고리가 존재하지 않습니다")
+		suggestions := categorySuggestions(catalog.SuggestCategories(storeType, suggestionCount))
+		return renderError(c, http.StatusNotFound, "카테고리가 존재하지 않습니다", suggestions)
 	}
+	if notModified(c, store.LatestModified(listStores)) {
+		return nil
+	}
+	cacheable(c, categoryCacheTag(do, si, storeType))
 	sort.Slice(listStores, func(i, j int) bool {
 		return listStores[i].DatePublished.UnixNano() > listStores[j].DatePublished.UnixNano()
 	})
+	sortBy := c.Query("sort")
+	if sortBy == "rating" {
+		sortByRating(reviewsOf(c), listStores)
+	}
 	var storeNames []string
 	for _, s := range listStores {
 		storeNames = append(storeNames, s.Title)
 	}
-	si = strings.Replace(si, "구", "", -1)
+	phoneNumber := store.ResolvePhoneNumber(&store.ContactTarget{
+		Type: storeType,
+		Do:   do,
+		Si:   listStores[0].Location.Si,
+	}, time.Now(), cfg.PhoneNumber)
+	si = listStores[0].Location.SiShort()
 	m := fiber.Map{}
 	m["Page"] = &PageConfig{
-		Path: c.Path(),
-		Author: &Author{
-			Name:        site.Config.Author,
-			ProfilePath: "/static/img/site/author/profile.png",
-		},
-		Title: fmt.Sprintf("[%s > %s > %s] 업소 목록", do, si, storeType),
+		Path:   pagePath(c),
+		Author: store.SiteAuthor(cfg),
+		Title:  fmt.Sprintf("[%s > %s > %s] 업소 목록", do, si, storeType),
 		Description: fmt.Sprintf("%s %s 지역에 %d개의 %s 업소가 있습니다: %s",
 			do, si, len(listStores), storeType, strings.Join(storeNames, ", ")),
 		Keywords: strings.Join(
 			[]string{fmt.Sprintf("%s %s %s 업소 목록", do, si, storeType)},
 			",",
 		),
-		PhoneNumber:   site.Config.PhoneNumber,
-		DatePublished: site.Config.DatePublished,
-		DateModified:  site.Config.DateModified,
+		PhoneNumber:   phoneNumber,
+		DatePublished: cfg.DatePublished,
+		DateModified:  cfg.DateModified,
 		ThumbnailPath: "/static/img/site/thumbnail/thumb.png",
+		OGImagePath:   categoryOGImagePath(do, listStores[0].Location.Si, storeType),
+	}
+	m["Profile"] = map[string]string{
+		"PhoneNumber": phoneNumber,
+		"CallPath":    callPath(categoryCallKey(do, listStores[0].Location.Si, storeType), c.Path()),
 	}
-	m["Profile"] = map[string]string{"PhoneNumber": listStores[0].PhoneNumber}
-	m["Breadcrumbs"] = map[string]string{"StoreType": listStores[0].Type}
+	m["Breadcrumbs"] = map[string]string{"Region": si, "StoreType": listStores[0].Type}
 	m["Stores"] = listStores
+	m["Sort"] = sortBy
 	return c.Status(http.StatusOK).Render("category/index", m, "layout/category")
 }
 
+// sortByRating: 평균 평점, 후기 수가 높은 순. 평점이 없는 가게는 뒤로
+func sortByRating(reviews *review.DB, list []*store.Store) {
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := reviews.Rating(list[i].Key()), reviews.Rating(list[j].Key())
+		switch {
+		case a == nil:
+			return false
+		case b == nil:
+			return true
+		case a.Average != b.Average:
+			return a.Average > b.Average
+		}
+		return a.Count > b.Count
+	})
+}
+
 // BaseURL = /category
 func handleCategory(r fiber.Router) {
 	h := &categoryHandler{}
@@ -0,0 +1,240 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jeonghoikun/colagom.com/store"
+)
+
+const (
+	// maxCompare: 한번에 비교할 수 있는 가게 수
+	maxCompare = 3
+	// compareCookie: 가게 카드의 비교 버튼으로 고른 가게 Key 목록
+	compareCookie = "compare"
+)
+
+// compareHeadcounts: 인원수 별 가격을 보여줄 인원. 가게 페이지와 같음
+var compareHeadcounts = []int{1, 2, 3, 4}
+
+type compareHour struct {
+	Open   string `json:"open"`
+	Closed string `json:"closed"`
+}
+
+type compareTotal struct {
+	People int `json:"people"`
+	// Part1, Part2: 금액 합계. 0이면 문의 또는 그 시간에 영업하지 않음
+	Part1 int `json:"part1"`
+	Part2 int `json:"part2"`
+}
+
+// compareStore: 비교 표의 한 열. JSON 응답에도 그대로 사용
+type compareStore struct {
+	Key    string `json:"key"`
+	Title  string `json:"title"`
+	Type   string `json:"type"`
+	URL    string `json:"url"`
+	Closed bool   `json:"closed"`
+	// ClosedReason: 폐업 사유. 영업중이면 빈 값
+	ClosedReason string `json:"closedReason,omitempty"`
+	Address      string `json:"address"`
+	BuildingName string `json:"buildingName,omitempty"`
+	// Station: 가장 가까운 역. ex) 강남역 도보 5분
+	Station string `json:"station,omitempty"`
+	// Part1, Part2: 영업시간. 없으면 nil
+	Part1       *compareHour    `json:"part1"`
+	Part2       *compareHour    `json:"part2"`
+	Part1Whisky int             `json:"part1Whisky"`
+	Part2Whisky int             `json:"part2Whisky"`
+	TC          int             `json:"tc"`
+	RT          int             `json:"rt"`
+	Totals      []*compareTotal `json:"totals"`
+	// RemovePath: 이 가게를 뺀 비교 페이지 주소
+	RemovePath string `json:"-"`
+}
+
+func newCompareHour(t *store.TimeType) *compareHour {
+	if t == nil || !t.Has {
+		return nil
+	}
+	return &compareHour{Open: t.Open, Closed: t.Closed}
+}
+
+func newCompareStore(c *fiber.Ctx, s *store.Store) *compareStore {
+	l := s.Location
+	x := &compareStore{
+		Key:          s.Key(),
+		Title:        s.Title,
+		Type:         s.Type,
+		URL:          siteOf(c).URL(s.Path()),
+		Closed:       s.Active.IsPermanentClosed,
+		Address:      fmt.Sprintf("%s %s %s", l.Do, l.Si, l.Street()),
+		BuildingName: l.BuildingName,
+		Part1:        newCompareHour(s.Hour.Part1),
+		Part2:        newCompareHour(s.Hour.Part2),
+		Part1Whisky:  s.Menu.Part1Whisky,
+		Part2Whisky:  s.Menu.Part2Whisky,
+		TC:           s.Menu.TC,
+		RT:           s.Menu.RT,
+	}
+	if x.Closed {
+		x.ClosedReason = s.Active.Reason
+	}
+	if len(s.Stations) > 0 {
+		x.Station = fmt.Sprintf("%s 도보 %d분", s.Stations[0].Station.Name, s.Stations[0].Minutes)
+	}
+	for _, n := range compareHeadcounts {
+		x.Totals = append(x.Totals, &compareTotal{People: n, Part1: s.PriceFor(1, n), Part2: s.PriceFor(2, n)})
+	}
+	return x
+}
+
+// compareKeys: 쉼표로 구분한 가게 Key 중 get(보통 Catalog.GetByKey)으로 찾은 가게. 중복은 빼고 앞에서부터 maxCompare개
+func compareKeys(get func(key string) (*store.Store, bool), v string) []*store.Store {
+	list := []*store.Store{}
+	seen := map[string]bool{}
+	for _, key := range strings.Split(v, ",") {
+		s, has := get(store.NFC(strings.TrimSpace(key)))
+		if !has || seen[s.Key()] || len(list) == maxCompare {
+			continue
+		}
+		seen[s.Key()] = true
+		list = append(list, s)
+	}
+	return list
+}
+
+// compareQuery: 비교 페이지의 canonical query. 가게가 없으면 빈 값. ex) stores=서울:...:에이원,서울:...:에프원
+func compareQuery(list []*store.Store) string {
+	if len(list) == 0 {
+		return ""
+	}
+	keys := []string{}
+	for _, s := range list {
+		keys = append(keys, s.Key())
+	}
+	return "stores=" + url.QueryEscape(strings.Join(keys, ","))
+}
+
+func comparePath(base string, list []*store.Store) string {
+	if q := compareQuery(list); q != "" {
+		return base + "?" + q
+	}
+	return base
+}
+
+// setCompareCookie: 비교 페이지에서 보고 있는 가게를 다음 비교 버튼의 시작 목록으로 저장
+func setCompareCookie(c *fiber.Ctx, list []*store.Store) {
+	c.Cookie(&fiber.Cookie{
+		Name:     compareCookie,
+		Value:    compareQuery(list),
+		Path:     "/",
+		Expires:  time.Now().Add(7 * 24 * time.Hour),
+		HTTPOnly: true,
+		SameSite: "Lax",
+	})
+}
+
+// cookieCompareStores: 쿠키에 저장한 비교 목록
+func cookieCompareStores(c *fiber.Ctx) []*store.Store {
+	v, err := url.ParseQuery(c.Cookies(compareCookie))
+	if err != nil {
+		return nil
+	}
+	return compareKeys(catalogOf(c).GetByKey, v.Get("stores"))
+}
+
+type compareHandler struct{}
+
+// GET /compare?stores=key,key
+// 같은 가게 목록은 하나의 주소가 되도록 query를 canonical 형태(중복 제거, 없는 가게 제거)로 301
+func (*compareHandler) page(c *fiber.Ctx) error {
+	cfg := siteOf(c)
+	list := compareKeys(catalogOf(c).GetByKey, c.Query("stores"))
+	if q := compareQuery(list); string(c.Request().URI().QueryString()) != q {
+		return c.Redirect(comparePath("/compare", list), http.StatusMovedPermanently)
+	}
+	setCompareCookie(c, list)
+	columns := []*compareStore{}
+	titles := []string{}
+	for i, s := range list {
+		x := newCompareStore(c, s)
+		rest := append(append([]*store.Store{}, list[:i]...), list[i+1:]...)
+		x.RemovePath = comparePath("/compare", rest)
+		columns = append(columns, x)
+		titles = append(titles, fmt.Sprintf("%s %s", s.Title, s.Type))
+	}
+	title := "가게 비교"
+	description := fmt.Sprintf("가게 카드의 비교 버튼으로 %d곳까지 골라 영업시간, 주대, 인원수 별 가격을 나란히 비교하세요", maxCompare)
+	if len(titles) > 0 {
+		title = strings.Join(titles, " vs ") + " 비교"
+		description = fmt.Sprintf("%s의 영업시간, 주대, TC, RT, 인원수 별 가격, 위치를 나란히 비교합니다", strings.Join(titles, ", "))
+	}
+	phoneNumber := sitePhoneNumber(cfg)
+	m := fiber.Map{
+		"Page": &PageConfig{
+			Path:          comparePath("/compare", list),
+			Author:        store.SiteAuthor(cfg),
+			Title:         title,
+			Description:   description,
+			Keywords:      strings.Join(titles, ","),
+			PhoneNumber:   phoneNumber,
+			DatePublished: cfg.DatePublished,
+			DateModified:  latestSiteModified(cfg, catalogOf(c)),
+			ThumbnailPath: "/static/img/site/thumbnail/thumb.png",
+			OGImagePath:   "/static/img/site/thumbnail/thumb.png",
+		},
+		"Profile": map[string]string{
+			"PhoneNumber": phoneNumber,
+			"CallPath":    callPath(siteCallKey, c.Path()),
+		},
+		"Columns":    columns,
+		"Headcounts": compareHeadcounts,
+		"JSONPath":   comparePath("/compare.json", list),
+		"Max":        maxCompare,
+	}
+	return c.Status(http.StatusOK).Render("compare/index", m, "layout/compare")
+}
+
+// GET /compare.json?stores=key,key
+func (*compareHandler) json(c *fiber.Ctx) error {
+	columns := []*compareStore{}
+	for _, s := range compareKeys(catalogOf(c).GetByKey, c.Query("stores")) {
+		columns = append(columns, newCompareStore(c, s))
+	}
+	return c.Status(http.StatusOK).JSON(fiber.Map{"stores": columns})
+}
+
+// POST /compare
+// 가게 카드의 비교 버튼. 쿠키의 목록에 store를 더해 비교 페이지로 이동. 가득 차면 가장 먼저 고른 가게를 뺌
+func (*compareHandler) add(c *fiber.Ctx) error {
+	s, has := catalogOf(c).GetByKey(c.FormValue("store"))
+	if !has {
+		return renderError(c, http.StatusNotFound, "가게를 찾을 수 없습니다", nil)
+	}
+	list := []*store.Store{}
+	for _, x := range cookieCompareStores(c) {
+		if x != s {
+			list = append(list, x)
+		}
+	}
+	list = append(list, s)
+	if len(list) > maxCompare {
+		list = list[len(list)-maxCompare:]
+	}
+	setCompareCookie(c, list)
+	return c.Redirect(comparePath("/compare", list), http.StatusSeeOther)
+}
+
+// BaseURL = /
+func handleCompare(r fiber.Router) {
+	h := &compareHandler{}
+	r.Get("/compare", h.page)
+	r.Get("/compare.json", h.json)
+	r.Post("/compare", h.add)
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jeonghoikun/colagom.com/store"
+	"golang.org/x/text/unicode/norm"
+)
+
+func compareStores(titles ...string) (map[string]*store.Store, func(string) (*store.Store, bool)) {
+	byKey := map[string]*store.Store{}
+	for _, title := range titles {
+		s := &store.Store{Location: &store.Location{Do: "서울", Si: "강남구", Dong: "역삼동"}, Type: "쩜오", Title: title}
+		byKey[s.Key()] = s
+	}
+	return byKey, func(key string) (*store.Store, bool) {
+		s, has := byKey[key]
+		return s, has
+	}
+}
+
+func compareTitles(list []*store.Store) string {
+	titles := []string{}
+	for _, s := range list {
+		titles = append(titles, s.Title)
+	}
+	return strings.Join(titles, ",")
+}
+
+func TestCompareKeys(t *testing.T) {
+	_, get := compareStores("에이원", "에프원", "달토", "유앤미")
+	key := func(title string) string { return "서울:강남구:역삼동:쩜오:" + title }
+	tests := []struct {
+		name, in, want string
+	}{
+		{"empty", "", ""},
+		{"one", key("에이원"), "에이원"},
+		{"keeps order", key("에프원") + "," + key("에이원"), "에프원,에이원"},
+		{"drops unknown", "nope," + key("에이원") + ",서울:강남구", "에이원"},
+		{"drops duplicates", key("에이원") + "," + key("에이원") + "," + key("에프원"), "에이원,에프원"},
+		{"trims spaces", " " + key("에이원") + " , " + key("에프원"), "에이원,에프원"},
+		{"nfd keys", norm.NFD.String(key("달토")), "달토"},
+		{"keeps first maxCompare", strings.Join([]string{key("에이원"), key("에프원"), key("달토"), key("유앤미")}, ","), "에이원,에프원,달토"},
+	}
+	for _, tt := range tests {
+		if got := compareTitles(compareKeys(get, tt.in)); got != tt.want {
+			t.Errorf("%s: compareKeys(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCompareQuery(t *testing.T) {
+	byKey, get := compareStores("에이원", "에프원")
+	if q := compareQuery(nil); q != "" {
+		t.Errorf("compareQuery(nil) = %q, want empty", q)
+	}
+	if p := comparePath("/compare", nil); p != "/compare" {
+		t.Errorf("comparePath(nil) = %q, want /compare", p)
+	}
+	list := []*store.Store{byKey["서울:강남구:역삼동:쩜오:에프원"], byKey["서울:강남구:역삼동:쩜오:에이원"]}
+	q := compareQuery(list)
+	v, err := url.ParseQuery(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := v.Get("stores"), "서울:강남구:역삼동:쩜오:에프원,서울:강남구:역삼동:쩜오:에이원"; got != want {
+		t.Errorf("stores = %q, want %q", got, want)
+	}
+	// canonical query를 다시 읽으면 같은 목록, 같은 query. 비교 페이지의 301이 반복되지 않음
+	again := compareKeys(get, v.Get("stores"))
+	if compareQuery(again) != q {
+		t.Errorf("compareQuery(compareKeys(%q)) = %q, want %q", v.Get("stores"), compareQuery(again), q)
+	}
+	if strings.ContainsAny(q[len("stores="):], ",:") {
+		t.Errorf("query value should be escaped: %q", q)
+	}
+}
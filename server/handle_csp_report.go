@@ -0,0 +1,56 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// cspReportMaxBytes: 이보다 큰 보고는 잘라서 기록
+const cspReportMaxBytes = 4 << 10
+
+// cspReport: report-uri로 보내는 application/csp-report 본문
+type cspReport struct {
+	Report struct {
+		DocumentURI        string `json:"document-uri"`
+		ViolatedDirective  string `json:"violated-directive"`
+		EffectiveDirective string `json:"effective-directive"`
+		BlockedURI         string `json:"blocked-uri"`
+		SourceFile         string `json:"source-file"`
+		LineNumber         int    `json:"line-number"`
+		Disposition        string `json:"disposition"`
+	} `json:"csp-report"`
+}
+
+type cspReportHandler struct{}
+
+// POST /csp-report
+// 위반 내용을 서버 로그에 남김
+func (*cspReportHandler) report(c *fiber.Ctx) error {
+	body := c.Body()
+	r := &cspReport{}
+	if err := json.Unmarshal(body, r); err != nil || r.Report.DocumentURI == "" {
+		if len(body) > cspReportMaxBytes {
+			body = body[:cspReportMaxBytes]
+		}
+		loggerOf(c).Warn("csp report", "site", siteOf(c).Domain, "body", string(body))
+		return c.SendStatus(http.StatusNoContent)
+	}
+	x := r.Report
+	loggerOf(c).Warn("csp violation",
+		"site", siteOf(c).Domain,
+		"disposition", x.Disposition,
+		"document", x.DocumentURI,
+		"blocked", x.BlockedURI,
+		"directive", x.EffectiveDirective,
+		"source", fmt.Sprintf("%s:%d", x.SourceFile, x.LineNumber))
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// BaseURL = /csp-report
+func handleCSPReport(r fiber.Router) {
+	h := &cspReportHandler{}
+	r.Post("/", h.report)
+}
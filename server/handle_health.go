@@ -0,0 +1,107 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jeonghoikun/colagom.com/store"
+)
+
+// health: /healthz, /readyz에 보여줄 서버 상태. 서버 전체에서 하나
+type health struct {
+	// shuttingDown: 종료 신호를 받은 뒤에는 준비 안됨
+	shuttingDown atomic.Bool
+
+	mu sync.Mutex
+	// templates: 사이트 도메인별 마지막 템플릿 파싱 결과
+	templates map[string]*templateState
+	// ogFont: 공유 이미지 폰트를 읽지 못한 이유. 빈 값이면 정상
+	ogFont string
+}
+
+type templateState struct {
+	CheckedAt time.Time `json:"checkedAt"`
+	Error     string    `json:"error,omitempty"`
+}
+
+func newHealth() *health {
+	return &health{templates: map[string]*templateState{}}
+}
+
+func (h *health) setTemplates(domain string, err error) {
+	st := &templateState{CheckedAt: time.Now()}
+	if err != nil {
+		st.Error = err.Error()
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.templates[domain] = st
+}
+
+// setOGFont: 폰트가 없어도 썸네일로 대신하므로 준비 상태에는 영향 없음
+func (h *health) setOGFont(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.ogFont = ""
+	if err != nil {
+		h.ogFont = err.Error()
+	}
+}
+
+// GET /healthz
+// 프로세스가 요청을 처리하고 있으면 항상 200
+func (*health) healthz(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(http.StatusOK).JSON(fiber.Map{"status": "ok"})
+}
+
+// GET /readyz
+// 카탈로그를 한번도 로드하지 못했거나, 템플릿 파싱에 실패했거나, 종료 중이면 503.
+// Reload 실패는 이전 카탈로그로 서비스하므로 lastError에만 표시. 공유 이미지 폰트 오류도 ogFont에만 표시
+func (h *health) readyz(c *fiber.Ctx) error {
+	ready := !h.shuttingDown.Load()
+	catalog := store.CurrentLoadState()
+	if catalog.LoadedAt.IsZero() || catalog.Stores == 0 {
+		ready = false
+	}
+	lastError := ""
+	if catalog.LastError != nil {
+		lastError = catalog.LastError.Error()
+	}
+	h.mu.Lock()
+	templates := map[string]*templateState{}
+	for domain, st := range h.templates {
+		templates[domain] = st
+		if st.Error != "" {
+			ready = false
+		}
+	}
+	ogFont := h.ogFont
+	h.mu.Unlock()
+
+	status, code := "ok", http.StatusOK
+	if !ready {
+		status, code = "unavailable", http.StatusServiceUnavailable
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(code).JSON(fiber.Map{
+		"status":       status,
+		"shuttingDown": h.shuttingDown.Load(),
+		"catalog": fiber.Map{
+			"loadedAt":  catalog.LoadedAt,
+			"stores":    catalog.Stores,
+			"lastError": lastError,
+		},
+		"templates": templates,
+		"ogFont":    fiber.Map{"error": ogFont},
+	})
+}
+
+// BaseURL = /
+func handleHealth(r fiber.Router, h *health) {
+	r.Get("/healthz", h.healthz)
+	r.Get("/readyz", h.readyz)
+}
@@ -2,8 +2,8 @@ package server
 
 import (
 	"fmt"
+	"html"
 	"net/http"
-	"net/url"
 	"strings"
 	"time"
 
@@ -14,25 +14,39 @@ import (
 
 type indexHandler struct{}
 
+// latestSiteModified: 사이트 설정과 사이트의 전체 가게 중 가장 최근 수정일
+func latestSiteModified(cfg *site.Site, catalog *store.Catalog) time.Time {
+	t := store.LatestModified(catalog.ListAllStores())
+	if cfg.DateModified.After(t) {
+		return cfg.DateModified
+	}
+	return t
+}
+
 // GET /
 func (*indexHandler) index(c *fiber.Ctx) error {
+	cfg := siteOf(c)
+	if notModified(c, latestSiteModified(cfg, catalogOf(c))) {
+		return nil
+	}
+	cacheable(c, "index")
+	phoneNumber := sitePhoneNumber(cfg)
 	m := fiber.Map{
 		"Page": &PageConfig{
-			Path: c.Path(),
-			Author: &Author{
-				Name:        site.Config.Author,
-				ProfilePath: "/static/img/site/author/profile.png",
-			},
-			Title:         site.Config.Title,
-			Description:   site.Config.Description,
-			Keywords:      site.Config.Keywords.String(),
-			PhoneNumber:   site.Config.PhoneNumber,
-			DatePublished: site.Config.DatePublished,
-			DateModified:  site.Config.DateModified,
+			Path:          pagePath(c),
+			Author:        store.SiteAuthor(cfg),
+			Title:         cfg.Title,
+			Description:   cfg.Description,
+			Keywords:      cfg.Keywords.String(),
+			PhoneNumber:   phoneNumber,
+			DatePublished: cfg.DatePublished,
+			DateModified:  cfg.DateModified,
 			ThumbnailPath: "/static/img/site/thumbnail/thumb.png",
+			OGImagePath:   "/static/img/site/thumbnail/thumb.png",
 		},
 		"Profile": map[string]string{
-			"PhoneNumber": site.Config.PhoneNumber,
+			"PhoneNumber": phoneNumber,
+			"CallPath":    callPath(siteCallKey, c.Path()),
 		},
 	}
 	return c.Status(http.StatusOK).Render("index", m, "layout/index")
@@ -40,37 +54,48 @@ func (*indexHandler) index(c *fiber.Ctx) error {
 
 // GET /robots.txt
 func (*indexHandler) robots(c *fiber.Ctx) error {
+	cfg := siteOf(c)
+	if notModified(c, cfg.DateModified) {
+		return nil
+	}
+	cacheable(c, "robots")
 	var ss []string
 	ss = append(ss, "User-agent: *")
 	ss = append(ss, "Allow: /")
-	ss = append(ss, fmt.Sprintf("Sitemap: https://%s/sitemap.xml", site.Config.Domain))
+	ss = append(ss, "Disallow: /call/")
+	ss = append(ss, "Sitemap: "+cfg.URL("/sitemap.xml"))
 	return c.Status(http.StatusOK).SendString(strings.Join(ss, "\n"))
 }
 
 // GET /sitemap.xml
 func (*indexHandler) sitemap(c *fiber.Ctx) error {
+	cfg := siteOf(c)
+	catalog := catalogOf(c)
+	if notModified(c, latestSiteModified(cfg, catalog)) {
+		return nil
+	}
+	cacheable(c, "sitemap")
 	var ss []string
 	ss = append(ss, `<?xml version="1.0" encoding="UTF-8"?>`)
 	ss = append(ss, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
 
-	host := fmt.Sprintf("https://%s", site.Config.Domain)
-	dateModified := site.Config.DateModified.Format(time.RFC3339)
+	// loc: canonical 주소. XML에 넣기 위해 &를 escape
+	loc := func(path string) string { return html.EscapeString(cfg.URL(path)) }
+	dateModified := cfg.DateModified.Format(time.RFC3339)
 
 	// index
 	ss = append(ss, `<url>`)
-	ss = append(ss, fmt.Sprintf(`<loc>%s/</loc>`, host))
+	ss = append(ss, fmt.Sprintf(`<loc>%s</loc>`, loc("/")))
 	ss = append(ss, fmt.Sprintf(`<lastmod>%s</lastmod>`, dateModified))
 	ss = append(ss, `</url>`)
 
 	// Custom: Categories by store type in Gangnam-gu, Seoul
 	categories := []string{}
-	for _, s := range store.ListAllStores() {
-		do := url.QueryEscape(s.Location.Do)
-		si := url.QueryEscape(s.Location.Si)
-		storeType := url.QueryEscape(s.Type)
+	for _, s := range catalog.ListAllStores() {
+		categoryPath := fmt.Sprintf("/category/%s/%s/%s", s.Location.Do, s.Location.Si, s.Type)
 		var has bool
 		for _, category := range categories {
-			if category == fmt.Sprintf("%s:%s:%s", do, si, storeType) {
+			if category == categoryPath {
 				has = true
 				break
 			}
@@ -79,27 +104,50 @@ func (*indexHandler) sitemap(c *fiber.Ctx) error {
 			continue
 		}
 		ss = append(ss, `<url>`)
-		ss = append(ss, fmt.Sprintf(`<loc>%s/category/%s/%s/%s</loc>`, host, do, si, storeType))
+		ss = append(ss, fmt.Sprintf(`<loc>%s</loc>`, loc(categoryPath)))
 		dateModified = s.DateModified.Format(time.RFC3339)
 		ss = append(ss, fmt.Sprintf(`<lastmod>%s</lastmod>`, dateModified))
 		ss = append(ss, `</url>`)
-		categories = append(categories, fmt.Sprintf("%s:%s:%s", do, si, storeType))
+		categories = append(categories, categoryPath)
 	}
 
 	// stores
-	for _, s := range store.ListAllStores() {
+	for _, s := range catalog.ListAllStores() {
 		ss = append(ss, `<url>`)
-		do := url.QueryEscape(s.Location.Do)
-		si := url.QueryEscape(s.Location.Si)
-		dong := url.QueryEscape(s.Location.Dong)
-		storeType := url.QueryEscape(s.Type)
-		storeTitle := url.QueryEscape(s.Title)
-		ss = append(ss, fmt.Sprintf(`<loc>%s/store/%s/%s/%s/%s/%s</loc>`, host, do, si, dong, storeType, storeTitle))
+		ss = append(ss, fmt.Sprintf(`<loc>%s</loc>`, loc(s.Path())))
 		dateModified = s.DateModified.Format(time.RFC3339)
 		ss = append(ss, fmt.Sprintf(`<lastmod>%s</lastmod>`, dateModified))
 		ss = append(ss, `</url>`)
 	}
 
+	// stations
+	for _, st := range catalog.ListStations(cfg.StationRadius) {
+		list := []*store.Store{}
+		for _, g := range catalog.ListStoresNearStation(st, cfg.StationRadius) {
+			for _, x := range g.Stores {
+				list = append(list, x.Store)
+			}
+		}
+		ss = append(ss, `<url>`)
+		ss = append(ss, fmt.Sprintf(`<loc>%s</loc>`, loc(st.Path())))
+		dateModified = store.LatestModified(list).Format(time.RFC3339)
+		ss = append(ss, fmt.Sprintf(`<lastmod>%s</lastmod>`, dateModified))
+		ss = append(ss, `</url>`)
+	}
+
+	// authors
+	for _, a := range store.ListAuthors() {
+		list := catalog.ListStoresByAuthor(cfg, a)
+		if len(list) == 0 {
+			continue
+		}
+		ss = append(ss, `<url>`)
+		ss = append(ss, fmt.Sprintf(`<loc>%s</loc>`, loc(a.Path())))
+		dateModified = store.LatestModified(list).Format(time.RFC3339)
+		ss = append(ss, fmt.Sprintf(`<lastmod>%s</lastmod>`, dateModified))
+		ss = append(ss, `</url>`)
+	}
+
 	ss = append(ss, `</urlset>`)
 	c.Response().Header.Add("Content-Type", "application/xml")
 	return c.Status(http.StatusOK).SendString(strings.Join(ss, "\n"))
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jeonghoikun/colagom.com/ogimage"
+	"github.com/jeonghoikun/colagom.com/site"
+	"github.com/jeonghoikun/colagom.com/store"
+)
+
+type ogHandler struct {
+	cache *ogimage.Cache
+}
+
+func priceLabel(price int) string {
+	if price == 0 {
+		return "가격 문의"
+	}
+	return fmt.Sprintf("₩%s~", humanize.Comma(int64(price)))
+}
+
+// send: 캐시된 이미지를 응답. 폰트가 없어서 만들 수 없으면 fallbackPath로 redirect
+func (h *ogHandler) send(c *fiber.Ctx, key string, modified time.Time, card *ogimage.Card, fallbackPath string) error {
+	if notModified(c, modified) {
+		return nil
+	}
+	b, err := h.cache.Get(key, modified, func() ([]byte, error) { return ogimage.Render(card) })
+	if errors.Is(err, ogimage.ErrNoFont) {
+		return c.Redirect(fallbackPath, http.StatusFound)
+	}
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).SendString(err.Error())
+	}
+	c.Set(fiber.HeaderContentType, "image/png")
+	return c.Status(http.StatusOK).Send(b)
+}
+
+// GET /og/store/:do/:si/:dong/:type/:title
+func (h *ogHandler) store(c *fiber.Ctx) error {
+	cfg := siteOf(c)
+	do, err := url.QueryUnescape(c.Params("do"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).SendString(err.Error())
+	}
+	si, err := url.QueryUnescape(c.Params("si"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).SendString(err.Error())
+	}
+	dong, err := url.QueryUnescape(c.Params("dong"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).SendString(err.Error())
+	}
+	storeType, err := url.QueryUnescape(c.Params("type"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).SendString(err.Error())
+	}
+	storeTitle, err := url.QueryUnescape(c.Params("title"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).SendString(err.Error())
+	}
+	s, has := catalogOf(c).Get(do, si, dong, storeType, storeTitle)
+	if !has {
+		return c.Status(http.StatusNotFound).SendString("Store not found")
+	}
+	logStoreKey(c, s.Key())
+	thumbnailPath := fmt.Sprintf("static/img/store/%s/%s/%s/%s/%s/thumbnail.png",
+		s.Location.Do, s.Location.Si, s.Location.Dong, s.Type, s.Title)
+	card := &ogimage.Card{
+		BackgroundPath: thumbnailPath,
+		Title:          s.Title,
+		Region:         fmt.Sprintf("%s %s %s", s.Location.Do, s.Location.SiShort(), s.Location.Dong),
+		Type:           s.Type,
+		IsClosed:       s.Active.IsPermanentClosed,
+		Badge:          "영업중",
+		Price:          priceLabel(s.StartingPrice()),
+		SiteTitle:      cfg.Title,
+	}
+	if s.Active.IsPermanentClosed {
+		card.Badge = "폐업"
+	}
+	return h.send(c, cfg.Domain+":store:"+s.Key(), s.DateModified, card, "/"+thumbnailPath)
+}
+
+// GET /og/category/:do/:si/:storeType
+func (h *ogHandler) category(c *fiber.Ctx) error {
+	cfg := siteOf(c)
+	do, err := url.QueryUnescape(c.Params("do"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).SendString(err.Error())
+	}
+	si, err := url.QueryUnescape(c.Params("si"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).SendString(err.Error())
+	}
+	storeType, err := url.QueryUnescape(c.Params("storeType"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).SendString(err.Error())
+	}
+	listStores := catalogOf(c).ListStoresByDoSiAndStoreType(do, si, storeType)
+	if len(listStores) == 0 {
+		return c.Status(http.StatusNotFound).SendString("카테고리가 존재하지 않습니다")
+	}
+	var (
+		background *store.Store
+		openCount  int
+		minPrice   int
+	)
+	for _, s := range listStores {
+		if s.Active.IsPermanentClosed {
+			continue
+		}
+		openCount++
+		if background == nil || s.DatePublished.After(background.DatePublished) {
+			background = s
+		}
+		if p := s.StartingPrice(); p != 0 && (minPrice == 0 || p < minPrice) {
+			minPrice = p
+		}
+	}
+	if background == nil {
+		background = listStores[0]
+	}
+	card := &ogimage.Card{
+		BackgroundPath: fmt.Sprintf("static/img/store/%s/%s/%s/%s/%s/thumbnail.png",
+			background.Location.Do, background.Location.Si, background.Location.Dong, background.Type, background.Title),
+		Title:     fmt.Sprintf("%s %s", listStores[0].Location.SiShort(), storeType),
+		Region:    fmt.Sprintf("%s %s", do, si),
+		Type:      fmt.Sprintf("%d개 업소", len(listStores)),
+		IsClosed:  openCount == 0,
+		Badge:     fmt.Sprintf("영업중 %d곳", openCount),
+		Price:     priceLabel(minPrice),
+		SiteTitle: cfg.Title,
+	}
+	key := fmt.Sprintf("%s:category:%s:%s:%s", cfg.Domain, do, si, storeType)
+	return h.send(c, key, store.LatestModified(listStores), card, "/static/img/site/thumbnail/thumb.png")
+}
+
+// BaseURL = /og
+func handleOG(r fiber.Router) {
+	h := &ogHandler{cache: ogimage.NewCache(site.Config.OGCacheDir)}
+	r.Get("/store/:do/:si/:dong/:type/:title", h.store)
+	r.Get("/category/:do/:si/:storeType", h.category)
+}
+
+func storeOGImagePath(s *store.Store) string {
+	return fmt.Sprintf("/og/store/%s/%s/%s/%s/%s",
+		url.PathEscape(s.Location.Do), url.PathEscape(s.Location.Si), url.PathEscape(s.Location.Dong),
+		url.PathEscape(s.Type), url.PathEscape(s.Title))
+}
+
+func categoryOGImagePath(do, si, storeType string) string {
+	return fmt.Sprintf("/og/category/%s/%s/%s",
+		url.PathEscape(do), url.PathEscape(si), url.PathEscape(storeType))
+}
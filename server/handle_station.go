@@ -0,0 +1,89 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jeonghoikun/colagom.com/station"
+	"github.com/jeonghoikun/colagom.com/store"
+)
+
+// stationCacheTag: 역 페이지는 가게가 바뀌면 모두 제거
+const stationCacheTag = "station"
+
+type stationHandler struct{}
+
+// GET /station/:name
+func (*stationHandler) page(c *fiber.Ctx) error {
+	cfg := siteOf(c)
+	name, err := url.QueryUnescape(c.Params("name"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).SendString(err.Error())
+	}
+	st, has := station.Get(store.NFC(name))
+	if !has || cfg.StationRadius == 0 {
+		return renderError(c, http.StatusNotFound, "역을 찾을 수 없습니다", nil)
+	}
+	groups := catalogOf(c).ListStoresNearStation(st, cfg.StationRadius)
+	if len(groups) == 0 {
+		return renderError(c, http.StatusNotFound, fmt.Sprintf("%s 근처에 소개한 업소가 없습니다", st.Name), nil)
+	}
+	listStores := []*store.Store{}
+	types := []string{}
+	keywords := []string{}
+	for _, g := range groups {
+		types = append(types, g.Type)
+		keywords = append(keywords, fmt.Sprintf("%s %s", st.Name, g.Type))
+		for _, x := range g.Stores {
+			listStores = append(listStores, x.Store)
+		}
+	}
+	modified := store.LatestModified(listStores)
+	if notModified(c, modified) {
+		return nil
+	}
+	cacheable(c, stationCacheTag)
+	walk := station.WalkMinutes(cfg.StationRadius)
+	phoneNumber := sitePhoneNumber(cfg)
+	m := fiber.Map{
+		"Page": &PageConfig{
+			Path:   pagePath(c),
+			Author: store.SiteAuthor(cfg),
+			Title:  fmt.Sprintf("%s %s 업소 목록", st.Name, strings.Join(types, ", ")),
+			Description: fmt.Sprintf("%s(%s)에서 걸어서 %d분 안에 있는 %d개 업소: %s",
+				st.Name, st.LineNames(), walk, len(listStores), storeTitles(listStores)),
+			Keywords:      strings.Join(keywords, ","),
+			PhoneNumber:   phoneNumber,
+			DatePublished: cfg.DatePublished,
+			DateModified:  modified,
+			ThumbnailPath: "/static/img/site/thumbnail/thumb.png",
+			OGImagePath:   "/static/img/site/thumbnail/thumb.png",
+		},
+		"Profile": map[string]string{
+			"PhoneNumber": phoneNumber,
+			"CallPath":    callPath(siteCallKey, c.Path()),
+		},
+		"Station": st,
+		"Groups":  groups,
+		"Walk":    walk,
+	}
+	return c.Status(http.StatusOK).Render("station/index", m, "layout/station")
+}
+
+// storeTitles: ex) 에이원, 에프원
+func storeTitles(list []*store.Store) string {
+	names := []string{}
+	for _, s := range list {
+		names = append(names, s.Title)
+	}
+	return strings.Join(names, ", ")
+}
+
+// BaseURL = /station
+func handleStation(r fiber.Router) {
+	h := &stationHandler{}
+	r.Get("/:name", h.page)
+}
@@ -4,17 +4,23 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
-	"strings"
+	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/jeonghoikun/colagom.com/site"
+	"github.com/jeonghoikun/colagom.com/review"
 	"github.com/jeonghoikun/colagom.com/store"
 )
 
+// reviewSchemaMax: JSON-LD에 넣는 최근 후기 수. 화면에는 모두 보임
+const reviewSchemaMax = 10
+
 type storeHandler struct{}
 
 // GET /store/:do/:si/:dong/:type/:title
 func (*storeHandler) page(c *fiber.Ctx) error {
+	cfg := siteOf(c)
+	catalog := catalogOf(c)
 	do, err := url.QueryUnescape(c.Params("do"))
 	if err != nil {
 		return c.Status(http.StatusBadRequest).SendString(err.Error())
@@ -35,46 +41,114 @@ func (*storeHandler) page(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(http.StatusBadRequest).SendString(err.Error())
 	}
-	store, has := store.Get(do, si, dong, storeType, storeTitle)
+	s, has := catalog.Get(do, si, dong, storeType, storeTitle)
 	if !has {
-		return c.Status(http.StatusNotFound).SendString("Store not found")
+		if r, removed := store.GetRemoval(do, si, dong, storeType, storeTitle); removed {
+			return renderError(c, http.StatusGone,
+				fmt.Sprintf("%s %s 정보는 삭제되었습니다 (%s)", r.Title, r.Type, r.Reason), nil)
+		}
+		if moved, has := catalog.MovedStore(do, storeType, storeTitle); has {
+			return c.Redirect(withQuery(c, (&url.URL{Path: moved.Path()}).EscapedPath()), http.StatusMovedPermanently)
+		}
+		suggestions := storeSuggestions(catalog.SuggestStores(dong, storeType, storeTitle, suggestionCount))
+		return renderError(c, http.StatusNotFound, "가게를 찾을 수 없습니다", suggestions)
+	}
+	logStoreKey(c, s.Key())
+	author := store.AuthorOf(cfg, s)
+	store := s
+	if notModified(c, store.DateModified) {
+		return nil
 	}
-	si = strings.Replace(si, "구", "", -1)
+	cacheable(c, storeCacheTag(store))
+	phoneNumber := store.PhoneNumber(time.Now(), cfg.PhoneNumber)
+	si = store.Location.SiShort()
 	title := fmt.Sprintf("%s %s %s", si, store.Title, store.Type)
 	if store.Active.IsPermanentClosed {
 		title += fmt.Sprintf(" (폐업: %s)", store.Active.Reason)
 	} else {
 		title += " (영업중)"
 	}
+	reviews := reviewsOf(c).Approved(store.Key())
 	m := fiber.Map{
 		"Page": &PageConfig{
-			Path: c.Path(),
-			Author: &Author{
-				Name:        site.Config.Author,
-				ProfilePath: "/static/img/site/author/profile.png",
-			},
+			Path:          pagePath(c),
+			Author:        author,
 			Title:         title,
 			Description:   store.Description,
 			Keywords:      store.Keywords.String(),
-			PhoneNumber:   store.PhoneNumber,
+			PhoneNumber:   phoneNumber,
 			DatePublished: store.DatePublished,
 			DateModified:  store.DateModified,
 			ThumbnailPath: fmt.Sprintf("/static/img/store/%s/%s/%s/%s/%s/thumbnail.png",
 				store.Location.Do, store.Location.Si, store.Location.Dong, store.Type, store.Title),
+			OGImagePath: storeOGImagePath(store),
 		},
 		"Profile": map[string]string{
-			"PhoneNumber": store.PhoneNumber,
+			"PhoneNumber": phoneNumber,
+			"CallPath":    callPath(store.Key(), c.Path()),
 		},
 		"Store":  store,
 		"SiMini": si,
+		// StationRadius: 이 거리 안의 역은 역 페이지로 링크
+		"StationRadius": cfg.StationRadius,
+		"Reviews": fiber.Map{
+			"Enabled":   reviewsOf(c) != nil,
+			"List":      reviews,
+			"Schema":    reviews[:min(len(reviews), reviewSchemaMax)],
+			"Rating":    reviewsOf(c).Rating(store.Key()),
+			"Submitted": c.Query("review") == "submitted",
+		},
 	}
 	embedFilePath := fmt.Sprintf("store/%s/%s/%s/%s/%s",
 		store.Location.Do, store.Location.Si, store.Location.Dong, store.Type, store.Title)
+	// Markdown 파일로 만든 가게는 변환해둔 본문(BodyHTML)을 사용
+	if store.IsMarkdown() {
+		embedFilePath = "components/store/markdown"
+	}
 	return c.Status(http.StatusOK).Render(embedFilePath, m, "layout/store")
 }
 
+// POST /store/:do/:si/:dong/:type/:title/reviews
+// 후기는 검사 대기 상태로 저장되고 관리자가 승인해야 가게 페이지에 보임
+func (*storeHandler) submitReview(c *fiber.Ctx) error {
+	reviews := reviewsOf(c)
+	if reviews == nil {
+		return renderError(c, http.StatusServiceUnavailable, "지금은 후기를 남길 수 없습니다", nil)
+	}
+	params := []string{}
+	for _, name := range []string{"do", "si", "dong", "type", "title"} {
+		v, err := url.QueryUnescape(c.Params(name))
+		if err != nil {
+			return c.Status(http.StatusBadRequest).SendString(err.Error())
+		}
+		params = append(params, v)
+	}
+	s, has := catalogOf(c).Get(params[0], params[1], params[2], params[3], params[4])
+	if !has {
+		return renderError(c, http.StatusNotFound, "가게를 찾을 수 없습니다", nil)
+	}
+	logStoreKey(c, s.Key())
+	back := []*Suggestion{{Title: fmt.Sprintf("%s %s 페이지로 돌아가기", s.Title, s.Type), Path: s.Path()}}
+	// website: 사람에게 보이지 않는 입력칸. 값이 있으면 스팸으로 보고 저장하지 않음
+	if c.FormValue("website") == "" {
+		rating, _ := strconv.Atoi(c.FormValue("rating"))
+		r := &review.Review{
+			StoreKey: s.Key(),
+			Rating:   rating,
+			Name:     c.FormValue("name"),
+			Body:     c.FormValue("body"),
+		}
+		if err := reviews.Submit(r); err != nil {
+			return renderError(c, http.StatusBadRequest, err.Error(), back)
+		}
+	}
+	path := (&url.URL{Path: s.Path()}).EscapedPath()
+	return c.Redirect(path+"?review=submitted#reviews", http.StatusSeeOther)
+}
+
 // BaseURL = /store
 func handleStore(r fiber.Router) {
 	h := &storeHandler{}
 	r.Get("/:do/:si/:dong/:type/:title", h.page)
+	r.Post("/:do/:si/:dong/:type/:title/reviews", h.submitReview)
 }
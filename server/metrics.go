@@ -0,0 +1,181 @@
+package server
+
+import (
+	"crypto/subtle"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/jeonghoikun/colagom.com/ratelimit"
+	"github.com/jeonghoikun/colagom.com/site"
+	"github.com/jeonghoikun/colagom.com/store"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	descCatalogStores = prometheus.NewDesc("colagom_catalog_stores",
+		"사이트 카탈로그의 업종, 영업 상태별 가게 수", []string{"site", "type", "status"}, nil)
+	descCatalogReloads = prometheus.NewDesc("colagom_catalog_reloads_total",
+		"서버 시작 후 카탈로그 Reload 결과별 횟수", []string{"result"}, nil)
+	descCatalogLoaded = prometheus.NewDesc("colagom_catalog_last_load_timestamp_seconds",
+		"마지막으로 카탈로그 로드에 성공한 시간", nil, nil)
+	descCacheRequests = prometheus.NewDesc("colagom_render_cache_requests_total",
+		"렌더링 캐시를 사용하는 route의 hit, miss 횟수", []string{"site", "route", "result"}, nil)
+	descCacheBytes = prometheus.NewDesc("colagom_render_cache_bytes",
+		"렌더링 캐시에 저장된 응답 크기", []string{"site"}, nil)
+	descCacheEntries = prometheus.NewDesc("colagom_render_cache_entries",
+		"렌더링 캐시에 저장된 페이지 수", []string{"site"}, nil)
+)
+
+// metrics: /metrics에 보여줄 Prometheus 지표. 서버 전체에서 하나.
+// 요청, 렌더링 시간은 요청마다 기록하고 카탈로그, 캐시는 수집할 때 계산
+type metrics struct {
+	registry *prometheus.Registry
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	renders  *prometheus.HistogramVec
+	// sites: 카탈로그, 렌더링 캐시를 수집할 사이트. 서버 시작 전에 채움
+	sites []*siteServer
+}
+
+func newMetrics() *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "colagom_http_requests_total",
+			Help: "route, 응답 코드별 요청 수",
+		}, []string{"site", "method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "colagom_http_request_duration_seconds",
+			Help:    "route별 응답 시간",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"site", "route"}),
+		renders: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "colagom_template_render_duration_seconds",
+			Help:    "템플릿별 렌더링 시간",
+			Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"site", "template"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests, m.duration, m.renders, m,
+	)
+	return m
+}
+
+func (m *metrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		descCatalogStores, descCatalogReloads, descCatalogLoaded,
+		descCacheRequests, descCacheBytes, descCacheEntries,
+	} {
+		ch <- d
+	}
+}
+
+func (m *metrics) Collect(ch chan<- prometheus.Metric) {
+	state := store.CurrentLoadState()
+	ch <- prometheus.MustNewConstMetric(descCatalogReloads, prometheus.CounterValue, float64(state.Reloads), "success")
+	ch <- prometheus.MustNewConstMetric(descCatalogReloads, prometheus.CounterValue, float64(state.ReloadFailures), "failure")
+	if !state.LoadedAt.IsZero() {
+		ch <- prometheus.MustNewConstMetric(descCatalogLoaded, prometheus.GaugeValue, float64(state.LoadedAt.Unix()))
+	}
+	for _, ss := range m.sites {
+		domain := ss.site.Domain
+		type group struct{ storeType, status string }
+		counts := map[group]int{}
+		for _, s := range ss.catalog.ListAllStores() {
+			status := "active"
+			if s.Active.IsPermanentClosed {
+				status = "closed"
+			}
+			counts[group{s.Type, status}]++
+		}
+		for g, n := range counts {
+			ch <- prometheus.MustNewConstMetric(descCatalogStores, prometheus.GaugeValue, float64(n), domain, g.storeType, g.status)
+		}
+		st := ss.cache.Stats()
+		for route, r := range st.Routes {
+			ch <- prometheus.MustNewConstMetric(descCacheRequests, prometheus.CounterValue, float64(r.Hits), domain, route, "hit")
+			ch <- prometheus.MustNewConstMetric(descCacheRequests, prometheus.CounterValue, float64(r.Misses), domain, route, "miss")
+		}
+		ch <- prometheus.MustNewConstMetric(descCacheBytes, prometheus.GaugeValue, float64(st.Bytes), domain)
+		ch <- prometheus.MustNewConstMetric(descCacheEntries, prometheus.GaugeValue, float64(st.Entries), domain)
+	}
+}
+
+// middleware: route별 요청 수, 응답 시간. 에러를 응답으로 바꾼 뒤의 코드를 세도록 logRequests보다 먼저 설치
+func (m *metrics) middleware(cfg *site.Site) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+		route, _ := routeOf(c)
+		status := strconv.Itoa(c.Response().StatusCode())
+		m.requests.WithLabelValues(cfg.Domain, c.Method(), route, status).Inc()
+		m.duration.WithLabelValues(cfg.Domain, route).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// timedViews: 템플릿 렌더링 시간을 기록하는 fiber.Views
+type timedViews struct {
+	fiber.Views
+	site    string
+	renders *prometheus.HistogramVec
+}
+
+func (m *metrics) views(cfg *site.Site, v fiber.Views) fiber.Views {
+	return &timedViews{Views: v, site: cfg.Domain, renders: m.renders}
+}
+
+func (v *timedViews) Render(w io.Writer, name string, bind interface{}, layouts ...string) error {
+	start := time.Now()
+	err := v.Views.Render(w, name, bind, layouts...)
+	v.renders.WithLabelValues(v.site, name).Observe(time.Since(start).Seconds())
+	return err
+}
+
+// metricsAccess: /metrics 접근 제한
+type metricsAccess struct {
+	// allow: 허용할 IP. ratelimit.BlockList를 IP 목록으로 사용
+	allow *ratelimit.BlockList
+	token string
+	ips   *clientIPs
+}
+
+func newMetricsAccess(cfg *site.Metrics, ips *clientIPs) (*metricsAccess, error) {
+	allow, err := ratelimit.ParseBlockList(cfg.Allow)
+	if err != nil {
+		return nil, err
+	}
+	return &metricsAccess{allow: allow, token: cfg.Token, ips: ips}, nil
+}
+
+// allowed: Allow는 프록시 헤더가 아닌 연결한 IP로 확인.
+// 프록시 뒤(IPHeader 설정)에서는 연결한 IP가 프록시이므로 Allow를 쓰지 않고 토큰만 허용
+func (a *metricsAccess) allowed(c *fiber.Ctx) bool {
+	if !a.ips.behindProxy() && a.allow.Contains(c.IP()) {
+		return true
+	}
+	token, found := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	return found && a.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) == 1
+}
+
+// GET /metrics
+// Prometheus text 형식. 허용하지 않은 요청은 403
+func (m *metrics) handler(access *metricsAccess) fiber.Handler {
+	h := adaptor.HTTPHandler(promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderCacheControl, "no-store")
+		if !access.allowed(c) {
+			return c.SendStatus(http.StatusForbidden)
+		}
+		return h(c)
+	}
+}
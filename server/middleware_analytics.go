@@ -0,0 +1,79 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jeonghoikun/colagom.com/analytics"
+	"github.com/jeonghoikun/colagom.com/site"
+)
+
+// pageRoute: 조회수를 모을 페이지 구분. 가게, 카테고리, 역, 메인이 아니면 빈 값
+func pageRoute(path string) string {
+	if path == "/" {
+		return "index"
+	}
+	if path == "/compare" {
+		return "compare"
+	}
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, p := range parts {
+		v, err := url.PathUnescape(p)
+		if err != nil {
+			return ""
+		}
+		parts[i] = v
+	}
+	switch {
+	case parts[0] == "store" && len(parts) == 6:
+		return strings.Join(parts, ":")
+	case parts[0] == "category" && len(parts) == 4:
+		return strings.Join(parts, ":")
+	case parts[0] == "station" && len(parts) == 2:
+		return strings.Join(parts, ":")
+	}
+	return ""
+}
+
+// referrerHost: Referer의 Host. 같은 사이트에서 이동했으면 internal
+func referrerHost(cfg *site.Site, referer string) string {
+	u, err := url.Parse(referer)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, h := range cfg.Hosts() {
+		if h == host {
+			return "internal"
+		}
+	}
+	return host
+}
+
+// recordViews: 페이지 조회수 기록. 렌더링 캐시와 304 응답도 조회로 셈
+func recordViews(cfg *site.Site, rec *analytics.Recorder) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if rec == nil || c.Method() != fiber.MethodGet {
+			return c.Next()
+		}
+		route := pageRoute(c.Path())
+		if route == "" {
+			return c.Next()
+		}
+		err := c.Next()
+		if status := c.Response().StatusCode(); status != http.StatusOK && status != http.StatusNotModified {
+			return err
+		}
+		rec.Record(&analytics.View{
+			Time:     time.Now(),
+			Site:     cfg.Domain,
+			Route:    route,
+			Referrer: referrerHost(cfg, c.Get(fiber.HeaderReferer)),
+			Agent:    analytics.Agent(c.Get(fiber.HeaderUserAgent)),
+		})
+		return err
+	}
+}
@@ -2,23 +2,71 @@ package server
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/jeonghoikun/colagom.com/review"
 	"github.com/jeonghoikun/colagom.com/site"
 	"github.com/jeonghoikun/colagom.com/store"
 )
 
-func bindSiteConfig(c *fiber.Ctx) error {
-	m := fiber.Map{
-		"Site": fiber.Map{
-			"Config": site.Config,
-			"Store": fiber.Map{
-				"Categories": store.ListAllCategories(),
-			},
-		},
+const (
+	localsSite    = "site"
+	localsCatalog = "catalog"
+	localsReviews = "reviews"
+)
+
+// siteOf: 요청 Host의 사이트 설정
+func siteOf(c *fiber.Ctx) *site.Site {
+	if cfg, ok := c.Locals(localsSite).(*site.Site); ok {
+		return cfg
+	}
+	return site.Config
+}
+
+// catalogOf: 요청 Host의 사이트에 포함된 가게 목록
+func catalogOf(c *fiber.Ctx) *store.Catalog {
+	if catalog, ok := c.Locals(localsCatalog).(*store.Catalog); ok {
+		return catalog
 	}
-	if err := c.Bind(m); err != nil {
-		return c.Status(http.StatusInternalServerError).SendString(err.Error())
+	return store.NewCatalog(nil)
+}
+
+// reviewsLocal: fasthttp는 요청이 끝나면 Locals 값 중 io.Closer를 Close 하므로
+// *review.DB를 직접 넣지 않음
+type reviewsLocal struct{ db *review.DB }
+
+// reviewsOf: 후기 저장소. 후기 기능이 꺼져 있으면 nil
+func reviewsOf(c *fiber.Ctx) *review.DB {
+	if l, ok := c.Locals(localsReviews).(*reviewsLocal); ok {
+		return l.db
+	}
+	return nil
+}
+
+// sitePhoneNumber: 가게, 카테고리 페이지가 아닌 곳(footer, 메인 등)에 보여줄 전화번호
+func sitePhoneNumber(cfg *site.Site) string {
+	return store.ResolvePhoneNumber(&store.ContactTarget{}, time.Now(), cfg.PhoneNumber)
+}
+
+func bindSite(cfg *site.Site, catalog *store.Catalog, reviews *review.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(localsSite, cfg)
+		c.Locals(localsCatalog, catalog)
+		c.Locals(localsReviews, &reviewsLocal{db: reviews})
+		m := fiber.Map{
+			"Site": fiber.Map{
+				"Config":      cfg,
+				"PhoneNumber": sitePhoneNumber(cfg),
+				"CallPath":    callPath(siteCallKey, c.Path()),
+				"Store": fiber.Map{
+					"Categories": catalog.ListAllCategories(),
+				},
+			},
+		}
+		if err := c.Bind(m); err != nil {
+			return c.Status(http.StatusInternalServerError).SendString(err.Error())
+		}
+		return c.Next()
 	}
-	return c.Next()
 }
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jeonghoikun/colagom.com/site"
+)
+
+// pagePath: PageConfig.Path. canonicalURL이 redirect하는 것과 같은 canonical 경로
+func pagePath(c *fiber.Ctx) string { return site.CanonicalPath(c.Path()) }
+
+// canonicalURL: GET, HEAD 요청을 canonical 주소(site.URL)로 301.
+// 경로가 canonical 형태가 아니거나, Host가 Domain이 아니거나, 프록시가 알려준 scheme(X-Forwarded-Proto)이 다르면 redirect.
+// 개발 모드는 localhost로 접속하므로 경로만 검사
+func canonicalURL(cfg *site.Site) fiber.Handler {
+	scheme := cfg.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+	return func(c *fiber.Ctx) error {
+		if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+			return c.Next()
+		}
+		raw := c.Path()
+		// 잘못된 인코딩은 핸들러에서 400
+		if _, err := url.PathUnescape(raw); err != nil {
+			return c.Next()
+		}
+		path := site.CanonicalPath(raw)
+		if site.Config.Dev {
+			if path == raw {
+				return c.Next()
+			}
+			return c.Redirect(withQuery(c, path), http.StatusMovedPermanently)
+		}
+		proto := c.Get(fiber.HeaderXForwardedProto)
+		if path == raw && site.HostName(string(c.Request().Host())) == cfg.Domain &&
+			(proto == "" || strings.EqualFold(proto, scheme)) {
+			return c.Next()
+		}
+		return c.Redirect(withQuery(c, cfg.URL(path)), http.StatusMovedPermanently)
+	}
+}
+
+func withQuery(c *fiber.Ctx, target string) string {
+	if q := c.Request().URI().QueryString(); len(q) > 0 {
+		return target + "?" + string(q)
+	}
+	return target
+}
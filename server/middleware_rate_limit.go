@@ -0,0 +1,98 @@
+package server
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jeonghoikun/colagom.com/analytics"
+	"github.com/jeonghoikun/colagom.com/ratelimit"
+	"github.com/jeonghoikun/colagom.com/site"
+)
+
+// rateLimiter: 서버 전체에서 하나. 여러 사이트가 같은 IP별 카운터를 사용
+type rateLimiter struct {
+	cfg     *site.RateLimit
+	backend ratelimit.Backend
+	block   *ratelimit.BlockList
+	// bots: nil이면 검색엔진 크롤러도 똑같이 제한
+	bots *ratelimit.Verifier
+	ips  *clientIPs
+}
+
+func newRateLimiter(cfg *site.RateLimit, backend ratelimit.Backend, resolver ratelimit.Resolver, ips *clientIPs) (*rateLimiter, error) {
+	block, err := ratelimit.ParseBlockList(cfg.Block)
+	if err != nil {
+		return nil, err
+	}
+	l := &rateLimiter{cfg: cfg, backend: backend, block: block, ips: ips}
+	if cfg.VerifyBots {
+		l.bots = ratelimit.NewVerifier(resolver)
+	}
+	return l, nil
+}
+
+// route: method, path에 맞는 경로별 제한 중 Prefix와 Suffix가 가장 긴 규칙. 없으면 nil
+func (l *rateLimiter) route(method, path string) *site.RouteLimit {
+	var match *site.RouteLimit
+	for _, r := range l.cfg.Routes {
+		if path != r.Prefix && !strings.HasPrefix(path, strings.TrimSuffix(r.Prefix, "/")+"/") {
+			continue
+		}
+		if (r.Suffix != "" && !strings.HasSuffix(path, r.Suffix)) || (r.Method != "" && r.Method != method) {
+			continue
+		}
+		if match == nil || len(r.Prefix)+len(r.Suffix) > len(match.Prefix)+len(match.Suffix) {
+			match = r
+		}
+	}
+	return match
+}
+
+// take: IP별 전체 제한과 경로별 제한에서 토큰을 하나씩 사용. 저장소 에러는 기록만 하고 허용
+func (l *rateLimiter) take(ip, method, path string, now time.Time) (bool, time.Duration) {
+	keys := []string{ip + "\x00*"}
+	limits := []site.Limit{l.cfg.Default}
+	if r := l.route(method, path); r != nil {
+		keys = append(keys, ip+"\x00"+r.Method+" "+r.Prefix+"*"+r.Suffix)
+		limits = append(limits, r.Limit)
+	}
+	for i, key := range keys {
+		ok, retryAfter, err := l.backend.Take(key, limits[i].Rate, limits[i].Burst, now)
+		if err != nil {
+			log.Printf("ratelimit: %s", err)
+			continue
+		}
+		if !ok {
+			return false, retryAfter
+		}
+	}
+	return true, 0
+}
+
+// middleware: 차단한 IP는 403, 제한을 넘으면 429와 Retry-After.
+// 확인된 검색엔진 크롤러는 제한을 넘어도 허용
+func (l *rateLimiter) middleware(c *fiber.Ctx) error {
+	ip := l.ips.ip(c)
+	if l.block.Contains(ip) {
+		return c.Status(http.StatusForbidden).SendString("접근이 차단되었습니다")
+	}
+	ok, retryAfter := l.take(ip, c.Method(), c.Path(), time.Now())
+	if ok {
+		return c.Next()
+	}
+	// 역방향 DNS 조회는 제한에 걸린 요청만
+	if agent := analytics.Agent(c.Get(fiber.HeaderUserAgent)); l.bots != nil && ratelimit.IsKnownBot(agent) && l.bots.Verify(ip, agent) {
+		return c.Next()
+	}
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfterSeconds(retryAfter)))
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(http.StatusTooManyRequests).SendString("요청이 너무 많습니다. 잠시 후 다시 시도하세요")
+}
+
+// retryAfterSeconds: Retry-After 헤더 값. 초 단위로 올림, 최소 1초
+func retryAfterSeconds(d time.Duration) int { return int(math.Max(1, math.Ceil(d.Seconds()))) }
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jeonghoikun/colagom.com/site"
+)
+
+func TestRateLimiterRoute(t *testing.T) {
+	l := &rateLimiter{cfg: &site.RateLimit{Routes: []*site.RouteLimit{
+		{Prefix: "/og"},
+		{Prefix: "/admin"},
+		{Prefix: "/admin/login"},
+		{Prefix: "/store", Suffix: "/reviews", Method: "POST"},
+	}}}
+	tests := []struct {
+		method, path string
+		want         string
+	}{
+		{"GET", "/og", "/og"},
+		{"GET", "/og/store/서울/강남구/역삼동/쩜오/에이원", "/og"},
+		{"GET", "/ogx", ""},
+		{"POST", "/admin/login", "/admin/login"},
+		{"GET", "/admin/stores", "/admin"},
+		{"GET", "/admin/loginx", "/admin"},
+		{"POST", "/store/서울/강남구/역삼동/쩜오/에이원/reviews", "/store*/reviews"},
+		{"GET", "/store/서울/강남구/역삼동/쩜오/에이원/reviews", ""},
+		{"POST", "/store/서울/강남구/역삼동/쩜오/에이원", ""},
+		{"GET", "/", ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if r := l.route(tt.method, tt.path); r != nil {
+			got = r.Prefix
+			if r.Suffix != "" {
+				got += "*" + r.Suffix
+			}
+		}
+		if got != tt.want {
+			t.Errorf("route(%s %s) = %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want int
+	}{
+		{0, 1},
+		{time.Millisecond, 1},
+		{999 * time.Millisecond, 1},
+		{time.Second, 1},
+		{time.Second + time.Millisecond, 2},
+		{10 * time.Minute, 600},
+	}
+	for _, tt := range tests {
+		if got := retryAfterSeconds(tt.d); got != tt.want {
+			t.Errorf("retryAfterSeconds(%s) = %d, want %d", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// panicError: 핸들러에서 발생한 panic. ex) Hour.Part2가 nil인 가게
+type panicError struct {
+	value interface{}
+	stack []byte
+}
+
+func (e *panicError) Error() string { return fmt.Sprintf("panic: %v", e.value) }
+
+// recoverPanic: panic을 요청 하나의 에러로 바꿈. stack은 요청 ID와 함께 기록하고
+// 응답은 errorHandler가 500 페이지로 만듦
+func recoverPanic(c *fiber.Ctx) (err error) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		pe := &panicError{value: r, stack: debug.Stack()}
+		loggerOf(c).Error("panic",
+			"error", pe.Error(),
+			"method", c.Method(),
+			"path", c.Path(),
+			"query", string(c.Request().URI().QueryString()),
+			"stack", string(pe.stack))
+		err = pe
+	}()
+	return c.Next()
+}
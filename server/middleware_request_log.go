@@ -0,0 +1,160 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jeonghoikun/colagom.com/analytics"
+)
+
+const (
+	localsRequestID = "requestID"
+	localsStoreKey  = "storeKey"
+	// localsCachedRoute: 렌더링 캐시로 응답한 페이지의 route. 핸들러를 거치지 않으므로 따로 기록
+	localsCachedRoute = "cachedRoute"
+
+	headerRequestID = "X-Request-ID"
+	// requestIDMaxLength: 프록시가 보낸 요청 ID를 그대로 쓰는 최대 길이
+	requestIDMaxLength = 64
+	// logErrorMaxBytes: 에러 응답 본문을 로그에 남기는 최대 길이
+	logErrorMaxBytes = 200
+)
+
+// requestIDOf: 요청 ID. 응답 헤더 X-Request-ID, 에러 페이지, 로그에 같은 값
+func requestIDOf(c *fiber.Ctx) string {
+	id, _ := c.Locals(localsRequestID).(string)
+	return id
+}
+
+// loggerOf: 요청 ID를 붙인 logger. 핸들러에서 요청과 함께 남길 로그에 사용
+func loggerOf(c *fiber.Ctx) *slog.Logger {
+	return slog.Default().With("id", requestIDOf(c))
+}
+
+// logStoreKey: 요청 로그에 가게 Key 기록. ex) 서울:강남구:역삼동:쩜오:에이원
+func logStoreKey(c *fiber.Ctx, key string) {
+	c.Locals(localsStoreKey, key)
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validRequestID: 로그, 헤더에 그대로 넣어도 되는 ID인지
+func validRequestID(id string) bool {
+	if id == "" || len(id) > requestIDMaxLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// routeOf: 요청이 맞은 route. 렌더링 캐시로 응답했으면 캐시한 페이지의 route, cached=true.
+// 어떤 route에도 맞지 않아 미들웨어(요청 제한, notFound)에서 끝난 요청은 unmatched
+func routeOf(c *fiber.Ctx) (route string, cached bool) {
+	if route, ok := c.Locals(localsCachedRoute).(string); ok {
+		return route, true
+	}
+	// app.Use("/", ...)의 route Path는 "/"라서 메인 페이지 외의 경로면 미들웨어에서 끝난 요청
+	if c.Route().Path == "/" && c.Path() != "/" {
+		return "unmatched", false
+	}
+	return c.Route().Path, false
+}
+
+// responseBytes: 응답 본문 크기. 파일 스트림은 Content-Length, 모르면 -1
+func responseBytes(c *fiber.Ctx) int {
+	if c.Response().IsBodyStream() {
+		return c.Response().Header.ContentLength()
+	}
+	return len(c.Response().Body())
+}
+
+// responseError: 핸들러가 SendString으로 보낸 에러 메세지. ex) url.QueryUnescape 실패
+func responseError(c *fiber.Ctx) string {
+	if c.Response().StatusCode() < http.StatusBadRequest || c.Response().IsBodyStream() {
+		return ""
+	}
+	if !strings.HasPrefix(string(c.Response().Header.ContentType()), fiber.MIMETextPlain) {
+		return ""
+	}
+	body := c.Response().Body()
+	if len(body) > logErrorMaxBytes {
+		body = body[:logErrorMaxBytes]
+	}
+	return string(body)
+}
+
+// logRequests: 요청마다 JSON 로그 한 줄. 프록시가 보낸 X-Request-ID가 있으면 그대로 사용.
+// 핸들러가 반환한 에러는 여기서 ErrorHandler로 응답을 만든 뒤 함께 기록
+func logRequests(ips *clientIPs) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		id := c.Get(headerRequestID)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Locals(localsRequestID, id)
+		c.Set(headerRequestID, id)
+
+		errMessage := ""
+		if err := c.Next(); err != nil {
+			errMessage = err.Error()
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(http.StatusInternalServerError)
+			}
+		} else {
+			errMessage = responseError(c)
+		}
+
+		status := c.Response().StatusCode()
+		level := slog.LevelInfo
+		switch {
+		case c.Path() == "/healthz" || c.Path() == "/readyz" || c.Path() == "/metrics":
+			level = slog.LevelDebug
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		route, cached := routeOf(c)
+		agent := analytics.Agent(c.Get(fiber.HeaderUserAgent))
+		attrs := []slog.Attr{
+			slog.String("id", id),
+			slog.String("method", c.Method()),
+			slog.String("host", c.Hostname()),
+			slog.String("path", c.Path()),
+			slog.String("route", route),
+			slog.Bool("cached", cached),
+			slog.Int("status", status),
+			slog.Float64("latencyMs", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", responseBytes(c)),
+			slog.String("ip", ips.ip(c)),
+			slog.Bool("bot", agent != analytics.Human),
+		}
+		if agent != analytics.Human {
+			attrs = append(attrs, slog.String("agent", agent))
+		}
+		if key, ok := c.Locals(localsStoreKey).(string); ok {
+			attrs = append(attrs, slog.String("store", key))
+		}
+		if errMessage != "" {
+			attrs = append(attrs, slog.String("error", errMessage))
+		}
+		slog.LogAttrs(c.UserContext(), level, "request", attrs...)
+		return nil
+	}
+}
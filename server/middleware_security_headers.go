@@ -0,0 +1,93 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jeonghoikun/colagom.com/site"
+)
+
+const (
+	// cspReportPath: 브라우저가 CSP 위반을 보고하는 경로
+	cspReportPath = "/csp-report"
+	localsNonce   = "cspNonce"
+)
+
+// nonceOf: 이 요청의 CSP nonce. Security 설정이 없으면 빈 값
+func nonceOf(c *fiber.Ctx) string {
+	nonce, _ := c.Locals(localsNonce).(string)
+	return nonce
+}
+
+// dropCSP: 304 응답의 CSP 헤더 제거. 브라우저는 304의 헤더로 저장한 응답의 헤더를 갱신하므로
+// 새 nonce를 보내면 저장한 본문의 nonce와 맞지 않게 됨
+func dropCSP(c *fiber.Ctx) {
+	c.Response().Header.Del(fiber.HeaderContentSecurityPolicy)
+	c.Response().Header.Del(fiber.HeaderContentSecurityPolicyReportOnly)
+}
+
+// newNonce: html/template이 이스케이프하지 않는 문자(RawURLEncoding)만 사용.
+// 본문의 nonce가 그대로여야 render_cache.go에서 찾아 바꿀 수 있음. ex) StdEncoding의 +는 &#43;가 됨
+func newNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// contentSecurityPolicy: 설정한 지시어의 script-src에 nonce를 붙이고 report-uri 추가
+func contentSecurityPolicy(cfg *site.Security, nonce string) string {
+	directives := []string{}
+	hasScriptSrc := false
+	for _, d := range cfg.CSP {
+		if name, _, _ := strings.Cut(d, " "); name == "script-src" {
+			d += " 'nonce-" + nonce + "'"
+			hasScriptSrc = true
+		}
+		directives = append(directives, d)
+	}
+	if !hasScriptSrc {
+		directives = append(directives, "script-src 'self' 'nonce-"+nonce+"'")
+	}
+	directives = append(directives, "report-uri "+cspReportPath)
+	return strings.Join(directives, "; ")
+}
+
+// securityHeaders: 보안 헤더 설정. 템플릿의 인라인 <script>는 nonce="{{.Nonce}}"를 붙여야 실행됨.
+// 캐시된 페이지도 본문의 nonce를 이 요청의 nonce로 바꿔서 보냄(render_cache.go)
+func securityHeaders(cfg *site.Security) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		nonce, err := newNonce()
+		if err != nil {
+			return c.Status(http.StatusInternalServerError).SendString(err.Error())
+		}
+		if err := c.Bind(fiber.Map{"Nonce": nonce}); err != nil {
+			return c.Status(http.StatusInternalServerError).SendString(err.Error())
+		}
+		c.Locals(localsNonce, nonce)
+		if cfg.HSTSMaxAge > 0 {
+			c.Set(fiber.HeaderStrictTransportSecurity,
+				"max-age="+strconv.Itoa(int(cfg.HSTSMaxAge.Seconds()))+"; includeSubDomains")
+		}
+		c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+		if cfg.ReferrerPolicy != "" {
+			c.Set(fiber.HeaderReferrerPolicy, cfg.ReferrerPolicy)
+		}
+		if cfg.PermissionsPolicy != "" {
+			c.Set(fiber.HeaderPermissionsPolicy, cfg.PermissionsPolicy)
+		}
+		if len(cfg.CSP) > 0 {
+			header := fiber.HeaderContentSecurityPolicy
+			if cfg.CSPReportOnly {
+				header = fiber.HeaderContentSecurityPolicyReportOnly
+			}
+			c.Set(header, contentSecurityPolicy(cfg, nonce))
+		}
+		return c.Next()
+	}
+}
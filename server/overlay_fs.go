@@ -0,0 +1,121 @@
+package server
+
+import (
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/jeonghoikun/colagom.com/store"
+)
+
+// overlayFS: 여러 디렉토리를 하나로 합친 http.FileSystem.
+// 같은 경로의 파일은 앞쪽 디렉토리의 것을 사용 (사이트별 템플릿 덮어쓰기).
+// 파일 이름은 NFC로 보여주고 열어서 디스크의 이름이 NFD여도 가게 경로로 템플릿을 찾음
+type overlayFS struct {
+	layers []http.Dir
+}
+
+func newOverlayFS(dirs ...string) *overlayFS {
+	o := &overlayFS{}
+	for _, d := range dirs {
+		o.layers = append(o.layers, http.Dir(d))
+	}
+	return o
+}
+
+func (o *overlayFS) Open(name string) (http.File, error) {
+	var first http.File
+	dirs := []http.File{}
+	for _, l := range o.layers {
+		f, err := openNFC(l, name)
+		if err != nil {
+			continue
+		}
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			continue
+		}
+		if !info.IsDir() {
+			if first == nil {
+				return f, nil
+			}
+			f.Close()
+			continue
+		}
+		if first == nil {
+			first = f
+		}
+		dirs = append(dirs, f)
+	}
+	if first == nil {
+		return nil, os.ErrNotExist
+	}
+	return &overlayDir{File: first, dirs: dirs}, nil
+}
+
+// overlayDir: 모든 layer의 같은 디렉토리 목록을 합쳐서 Readdir
+type overlayDir struct {
+	http.File
+	dirs []http.File
+}
+
+func (d *overlayDir) Readdir(count int) ([]fs.FileInfo, error) {
+	seen := map[string]bool{}
+	list := []fs.FileInfo{}
+	for _, f := range d.dirs {
+		infos, err := f.Readdir(-1)
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range infos {
+			if name := store.NFC(info.Name()); name != info.Name() {
+				info = &nfcFileInfo{FileInfo: info, name: name}
+			}
+			if seen[info.Name()] {
+				continue
+			}
+			seen[info.Name()] = true
+			list = append(list, info)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+	if count > 0 && len(list) > count {
+		list = list[:count]
+	}
+	return list, nil
+}
+
+func (d *overlayDir) Close() error {
+	for _, f := range d.dirs {
+		f.Close()
+	}
+	return nil
+}
+
+// nfcFileInfo: NFD 이름을 NFC로 바꿔서 보여주는 FileInfo
+type nfcFileInfo struct {
+	fs.FileInfo
+	name string
+}
+
+func (i *nfcFileInfo) Name() string { return i.name }
+
+// openNFC: name으로 열리지 않으면 디스크의 NFD 이름을 찾아서 염
+func openNFC(d http.Dir, name string) (http.File, error) {
+	f, err := d.Open(name)
+	if err == nil || !os.IsNotExist(err) {
+		return f, err
+	}
+	real, has := store.FindNFC(filepath.Join(string(d), filepath.FromSlash(name)))
+	if !has {
+		return nil, err
+	}
+	rel, rerr := filepath.Rel(string(d), real)
+	if rerr != nil {
+		return nil, err
+	}
+	return d.Open("/" + filepath.ToSlash(rel))
+}
@@ -0,0 +1,280 @@
+package server
+
+import (
+	"bytes"
+	"container/list"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jeonghoikun/colagom.com/store"
+)
+
+const localsCacheTags = "renderCacheTags"
+
+// noncePlaceholder: 저장한 본문에서 렌더링할 때의 nonce 자리. 보낼 때 요청마다 만든 nonce로 바꿈
+var noncePlaceholder = []byte("{{render-cache-nonce}}")
+
+// cacheable: 핸들러에서 호출하면 응답(200)이 renderCache에 저장됨.
+// tags는 선택적으로 무효화할 때 사용. ex) store:서울:강남구:역삼동:쩜오:에프원
+func cacheable(c *fiber.Ctx, tags ...string) {
+	c.Locals(localsCacheTags, tags)
+}
+
+func storeCacheTag(s *store.Store) string { return "store:" + s.Key() }
+
+func categoryCacheTag(do, si, storeType string) string {
+	return "category:" + do + ":" + si + ":" + storeType
+}
+
+type cachedPage struct {
+	key   string
+	route string
+	// storeKey: 요청 로그에 남길 가게 Key. 가게 페이지가 아니면 빈 값
+	storeKey     string
+	tags         []string
+	contentType  string
+	etag         string
+	lastModified string
+	cacheControl string
+	// body: 압축 전 본문. nonce는 noncePlaceholder로 저장
+	body []byte
+}
+
+func (p *cachedPage) size() int { return len(p.key) + len(p.body) }
+
+type routeStats struct {
+	Hits   uint64  `json:"hits"`
+	Misses uint64  `json:"misses"`
+	Rate   float64 `json:"hitRate"`
+}
+
+// renderCache: 렌더링한 응답을 저장하는 LRU 캐시. maxBytes를 넘으면 오래된 페이지부터 제거
+type renderCache struct {
+	mu       sync.Mutex
+	maxBytes int
+	size     int
+	lru      *list.List
+	items    map[string]*list.Element
+	stats    map[string]*routeStats
+}
+
+func newRenderCache(maxBytes int) *renderCache {
+	return &renderCache{
+		maxBytes: maxBytes,
+		lru:      list.New(),
+		items:    map[string]*list.Element{},
+		stats:    map[string]*routeStats{},
+	}
+}
+
+func (rc *renderCache) stat(route string) *routeStats {
+	st, has := rc.stats[route]
+	if !has {
+		st = &routeStats{}
+		rc.stats[route] = st
+	}
+	return st
+}
+
+func (rc *renderCache) get(key string) (*cachedPage, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	e, has := rc.items[key]
+	if !has {
+		return nil, false
+	}
+	rc.lru.MoveToFront(e)
+	p := e.Value.(*cachedPage)
+	rc.stat(p.route).Hits++
+	return p, true
+}
+
+func (rc *renderCache) miss(route string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.stat(route).Misses++
+}
+
+func (rc *renderCache) remove(e *list.Element) {
+	p := rc.lru.Remove(e).(*cachedPage)
+	delete(rc.items, p.key)
+	rc.size -= p.size()
+}
+
+func (rc *renderCache) set(p *cachedPage) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if p.size() > rc.maxBytes {
+		return
+	}
+	if e, has := rc.items[p.key]; has {
+		rc.remove(e)
+	}
+	rc.items[p.key] = rc.lru.PushFront(p)
+	rc.size += p.size()
+	for rc.size > rc.maxBytes {
+		rc.remove(rc.lru.Back())
+	}
+}
+
+// Invalidate: tags 중 하나라도 가진 페이지 제거
+func (rc *renderCache) Invalidate(tags ...string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	want := map[string]bool{}
+	for _, t := range tags {
+		want[t] = true
+	}
+	for e := rc.lru.Front(); e != nil; {
+		next := e.Next()
+		for _, t := range e.Value.(*cachedPage).tags {
+			if want[t] {
+				rc.remove(e)
+				break
+			}
+		}
+		e = next
+	}
+}
+
+func (rc *renderCache) Purge() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.lru.Init()
+	rc.items = map[string]*list.Element{}
+	rc.size = 0
+}
+
+// onCatalogChange: 구성이 바뀌면 header/footer 목록이 모든 페이지에 있으므로 전부 제거,
+// 아니면 바뀐 가게와 그 가게가 보이는 페이지만 제거
+func (rc *renderCache) onCatalogChange(ch *store.Change) {
+	if ch.Structural {
+		rc.Purge()
+		return
+	}
+	tags := []string{"index", "sitemap", authorCacheTag, stationCacheTag}
+	for _, s := range ch.Stores {
+		tags = append(tags, storeCacheTag(s), categoryCacheTag(s.Location.Do, s.Location.Si, s.Type))
+	}
+	rc.Invalidate(tags...)
+}
+
+// onReviewChange: 평점은 가게 페이지와 가게 카드가 있는 목록 페이지에 보임
+func (rc *renderCache) onReviewChange(storeKey string) {
+	tags := []string{"index", authorCacheTag, stationCacheTag}
+	if s, has := store.FindStore(storeKey); has {
+		tags = append(tags, storeCacheTag(s), categoryCacheTag(s.Location.Do, s.Location.Si, s.Type))
+	}
+	rc.Invalidate(tags...)
+}
+
+type renderCacheStats struct {
+	Entries  int                    `json:"entries"`
+	Bytes    int                    `json:"bytes"`
+	MaxBytes int                    `json:"maxBytes"`
+	Routes   map[string]*routeStats `json:"routes"`
+	Pages    []string               `json:"pages"`
+}
+
+func (rc *renderCache) Stats() *renderCacheStats {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	st := &renderCacheStats{
+		Entries:  len(rc.items),
+		Bytes:    rc.size,
+		MaxBytes: rc.maxBytes,
+		Routes:   map[string]*routeStats{},
+		Pages:    []string{},
+	}
+	for route, r := range rc.stats {
+		x := *r
+		if total := x.Hits + x.Misses; total > 0 {
+			x.Rate = float64(x.Hits) / float64(total)
+		}
+		st.Routes[route] = &x
+	}
+	for key := range rc.items {
+		st.Pages = append(st.Pages, key)
+	}
+	sort.Strings(st.Pages)
+	return st
+}
+
+// middleware: compress 미들웨어 뒤에 설치. 압축 전 본문을 저장해야 캐시된 페이지에도 요청마다 새 nonce를 넣을 수 있음.
+// 처음에는 compress 앞에 두고 압축한 본문을 저장했지만, CSP nonce가 요청마다 달라야 하므로 캐시 hit도 요청마다 다시 압축함(Level 2).
+// 렌더링보다 훨씬 싸고, 인코딩별로 따로 저장하지 않아도 되어 메모리도 덜 씀. CSP 헤더는 securityHeaders가 요청마다 설정
+func (rc *renderCache) middleware(c *fiber.Ctx) error {
+	if c.Method() != fiber.MethodGet {
+		return c.Next()
+	}
+	key := c.Path() + "?" + string(c.Request().URI().QueryString())
+	if p, has := rc.get(key); has {
+		c.Locals(localsCachedRoute, p.route)
+		if p.storeKey != "" {
+			logStoreKey(c, p.storeKey)
+		}
+		return p.send(c)
+	}
+	if err := c.Next(); err != nil {
+		return err
+	}
+	tags, ok := c.Locals(localsCacheTags).([]string)
+	if !ok {
+		return nil
+	}
+	rc.miss(c.Route().Path)
+	res := c.Response()
+	if res.StatusCode() != http.StatusOK {
+		return nil
+	}
+	storeKey, _ := c.Locals(localsStoreKey).(string)
+	body := append([]byte{}, res.Body()...)
+	if nonce := nonceOf(c); nonce != "" {
+		body = bytes.ReplaceAll(body, []byte(nonce), noncePlaceholder)
+	}
+	rc.set(&cachedPage{
+		key:          key,
+		route:        c.Route().Path,
+		storeKey:     storeKey,
+		tags:         tags,
+		contentType:  string(res.Header.ContentType()),
+		etag:         string(res.Header.Peek(fiber.HeaderETag)),
+		lastModified: string(res.Header.Peek(fiber.HeaderLastModified)),
+		cacheControl: string(res.Header.Peek(fiber.HeaderCacheControl)),
+		body:         body,
+	})
+	return nil
+}
+
+func (p *cachedPage) notModified(c *fiber.Ctx) bool {
+	if inm := c.Get(fiber.HeaderIfNoneMatch); inm != "" {
+		return p.etag != "" && etagMatches(inm, p.etag)
+	}
+	ims, err := http.ParseTime(c.Get(fiber.HeaderIfModifiedSince))
+	if err != nil {
+		return false
+	}
+	lm, err := http.ParseTime(p.lastModified)
+	return err == nil && !lm.After(ims)
+}
+
+func (p *cachedPage) send(c *fiber.Ctx) error {
+	if p.etag != "" {
+		c.Set(fiber.HeaderETag, p.etag)
+	}
+	if p.lastModified != "" {
+		c.Set(fiber.HeaderLastModified, p.lastModified)
+	}
+	if p.cacheControl != "" {
+		c.Set(fiber.HeaderCacheControl, p.cacheControl)
+	}
+	if p.notModified(c) {
+		dropCSP(c)
+		c.Status(http.StatusNotModified)
+		return nil
+	}
+	c.Set(fiber.HeaderContentType, p.contentType)
+	return c.Status(http.StatusOK).Send(bytes.ReplaceAll(p.body, noncePlaceholder, []byte(nonceOf(c))))
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jeonghoikun/colagom.com/site"
+)
+
+var (
+	headerNonce = regexp.MustCompile(`'nonce-([^']*)'`)
+	bodyNonce   = regexp.MustCompile(`nonce="([^"]*)"`)
+)
+
+// 캐시된 페이지도 본문의 nonce가 CSP 헤더의 nonce와 같아야 인라인 스크립트가 실행됨
+func TestRenderCacheNonce(t *testing.T) {
+	tmpl := template.Must(template.New("page").Parse(`<script nonce="{{.Nonce}}">run()</script>`))
+	rc := newRenderCache(1 << 20)
+	app := fiber.New()
+	app.Use(securityHeaders(&site.Security{CSP: []string{"default-src 'self'"}}))
+	app.Use(rc.middleware)
+	app.Get("/", func(c *fiber.Ctx) error {
+		cacheable(c, "index")
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+		return tmpl.Execute(c, fiber.Map{"Nonce": nonceOf(c)})
+	})
+
+	seen := map[string]bool{}
+	// 렌더링할 때의 nonce에 html/template이 이스케이프하는 문자가 있으면 저장한 본문에서 찾지 못함.
+	// 페이지마다 새로 렌더링(miss)한 뒤 캐시(hit)로 한번 더 받음
+	for i := 0; i < 50; i++ {
+		for _, hit := range []bool{false, true} {
+			res, err := app.Test(httptest.NewRequest(fiber.MethodGet, fmt.Sprintf("/?page=%d", i), nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			csp := res.Header.Get(fiber.HeaderContentSecurityPolicy)
+			h := headerNonce.FindStringSubmatch(csp)
+			b := bodyNonce.FindStringSubmatch(string(body))
+			if h == nil || b == nil {
+				t.Fatalf("page %d (hit=%v): nonce not found. header=%q body=%q", i, hit, csp, body)
+			}
+			// 브라우저는 속성 값의 문자 참조를 풀어서 비교
+			if got := html.UnescapeString(b[1]); got != h[1] {
+				t.Fatalf("page %d (hit=%v): body nonce %q, header nonce %q", i, hit, got, h[1])
+			}
+			if seen[h[1]] {
+				t.Fatalf("page %d (hit=%v): nonce %q reused", i, hit, h[1])
+			}
+			seen[h[1]] = true
+			if strings.Contains(string(body), string(noncePlaceholder)) {
+				t.Fatalf("page %d (hit=%v): placeholder left in body", i, hit)
+			}
+		}
+	}
+	if st := rc.Stats(); st.Routes["/"].Hits != 50 {
+		t.Errorf("page was not served from the cache: %+v", st)
+	}
+}
@@ -1,38 +1,79 @@
 package server
 
 import (
+	"context"
 	"fmt"
-	"math/rand"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/dustin/go-humanize"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/compress"
 	"github.com/gofiber/template/html/v2"
+	"github.com/jeonghoikun/colagom.com/analytics"
+	"github.com/jeonghoikun/colagom.com/calllog"
+	"github.com/jeonghoikun/colagom.com/ogimage"
+	"github.com/jeonghoikun/colagom.com/ratelimit"
+	"github.com/jeonghoikun/colagom.com/review"
 	"github.com/jeonghoikun/colagom.com/site"
+	"github.com/jeonghoikun/colagom.com/store"
+	"github.com/valyala/fasthttp"
 )
 
 type port uint32
 
 func (p *port) String() string { return fmt.Sprintf(":%d", *p) }
 
+// Server: 사이트마다 fiber app을 하나씩 만들고 Host 헤더로 요청을 나눔
 type Server struct {
-	port *port
-	app  *fiber.App
+	port   *port
+	sites  []*siteServer
+	server *fasthttp.Server
+	health *health
+	// metrics: 모든 사이트의 Prometheus 지표
+	metrics *metrics
+	// pageViews, reviews: 종료할 때 닫음. 열지 못했으면 nil
+	pageViews *analytics.Recorder
+	reviews   *review.DB
 }
 
-type engineFunc struct{}
+// siteServer: 사이트 하나를 서비스하는 fiber app
+type siteServer struct {
+	site    *site.Site
+	catalog *store.Catalog
+	app     *fiber.App
+	cache   *renderCache
+	calls   *calllog.Log
+	// pageViews: nil이면 조회수를 기록하지 않음
+	pageViews *analytics.Recorder
+	// reviews: nil이면 후기 기능 꺼짐
+	reviews *review.DB
+	// limiter: nil이면 요청을 제한하지 않음
+	limiter *rateLimiter
+	health  *health
+	metrics *metrics
+	ips     *clientIPs
+	// metricsAccess: nil이면 /metrics 비활성화
+	metricsAccess *metricsAccess
+}
+
+type engineFunc struct {
+	site    *site.Site
+	reviews *review.DB
+}
 
 func (*engineFunc) time() time.Time { return time.Now() }
 
-func (*engineFunc) withHost(s string) string {
-	return fmt.Sprintf("https://%s%s", site.Config.Domain, s)
+func (ef *engineFunc) withHost(s string) string {
+	return ef.site.URL(s)
 }
 
-func (*engineFunc) randFileNumber() int {
-	min := 0
-	max := 1000
-	return rand.Intn(max-min) + min
+func (ef *engineFunc) assetVersion() string {
+	return assetVersion(filepath.Join(ef.site.StaticDir, "css/main.css"))
 }
 
 func (*engineFunc) commaByPrice(prices ...int) string {
@@ -48,6 +89,11 @@ func (*engineFunc) commaByPrice(prices ...int) string {
 
 func (*engineFunc) multiply(a, b int) int { return a * b }
 
+func (*engineFunc) add(a, b int) int { return a + b }
+
+// storeRating: 가게 평균 평점. 승인된 후기가 없으면 nil
+func (ef *engineFunc) storeRating(key string) *review.Rating { return ef.reviews.Rating(key) }
+
 func (*engineFunc) listNumbers(ns ...int) []int {
 	list := []int{}
 	for _, n := range ns {
@@ -56,61 +102,296 @@ func (*engineFunc) listNumbers(ns ...int) []int {
 	return list
 }
 
-func engine() *html.Engine {
-	e := html.New("./views", ".html")
+// viewsDirs: 사이트 전용 템플릿 디렉토리가 있으면 ./views보다 먼저 찾음
+func viewsDirs(cfg *site.Site) []string {
+	if cfg.ViewsDir == "" {
+		return []string{"./views"}
+	}
+	return []string{cfg.ViewsDir, "./views"}
+}
+
+func engine(cfg *site.Site, reviews *review.DB) *viewEngine {
+	e := html.NewFileSystem(newOverlayFS(viewsDirs(cfg)...), ".html")
 	e.Reload(true)
-	ef := &engineFunc{}
+	ef := &engineFunc{site: cfg, reviews: reviews}
 	e.AddFunc("Time", ef.time)
 	e.AddFunc("WithHost", ef.withHost)
-	e.AddFunc("RandFileNumber", ef.randFileNumber)
+	e.AddFunc("AssetVersion", ef.assetVersion)
 	e.AddFunc("CommaByPrice", ef.commaByPrice)
 	e.AddFunc("Multiply", ef.multiply)
+	e.AddFunc("Add", ef.add)
 	e.AddFunc("ListNumbers", ef.listNumbers)
-	return e
+	e.AddFunc("StoreRating", ef.storeRating)
+	return &viewEngine{Engine: e}
+}
+
+func newSiteServer(cfg *site.Site, calls *calllog.Log, pageViews *analytics.Recorder, reviews *review.DB, m *metrics) *siteServer {
+	app := fiber.New(fiber.Config{
+		AppName:      cfg.Domain,
+		ServerHeader: cfg.Domain,
+		Views:        m.views(cfg, engine(cfg, reviews)),
+		// 관리자 화면 이미지 업로드
+		BodyLimit:    32 << 20,
+		ErrorHandler: errorHandler,
+	})
+	cache := newRenderCache(site.Config.RenderCacheMaxBytes)
+	store.Subscribe(cache.onCatalogChange)
+	// 전화번호는 모든 페이지(footer)에 있으므로 규칙이나 시간대가 바뀌면 전부 제거
+	store.SubscribeContacts(cache.Purge)
+	if reviews != nil {
+		reviews.Subscribe(cache.onReviewChange)
+	}
+	return &siteServer{
+		site:      cfg,
+		catalog:   store.NewCatalog(cfg.Includes),
+		app:       app,
+		cache:     cache,
+		calls:     calls,
+		pageViews: pageViews,
+		reviews:   reviews,
+		metrics:   m,
+	}
 }
 
 func New(portNumber uint32) *Server {
+	fontErr := ogimage.LoadFont(site.Config.OGFontPath)
+	if fontErr != nil {
+		log.Printf("ogimage: %s. 공유 이미지 대신 썸네일을 사용합니다", fontErr)
+	}
+	dirs := []string{"./views"}
+	for _, cfg := range site.Sites {
+		if cfg.ViewsDir != "" {
+			dirs = append(dirs, cfg.ViewsDir)
+		}
+	}
+	v, err := computeTemplateVersion(dirs...)
+	if err != nil {
+		log.Printf("server: template version: %s", err)
+	}
+	templateVersion.Store(v)
 	p := port(portNumber)
-	app := fiber.New(fiber.Config{
-		AppName:      site.Config.Domain,
-		ServerHeader: site.Config.Domain,
-		Views:        engine(),
+	s := &Server{port: &p, health: newHealth(), metrics: newMetrics()}
+	s.health.setOGFont(fontErr)
+	calls := calllog.New(filepath.Join(site.Config.DataDir, "calls.jsonl"))
+	pageViews, err := openPageViews()
+	if err != nil {
+		log.Printf("analytics: %s. 조회수를 기록하지 않습니다", err)
+	} else {
+		go pageViews.Run(time.Minute)
+	}
+	reviews, err := openReviews()
+	if err != nil {
+		log.Printf("review: %s. 후기 기능을 끕니다", err)
+	}
+	s.pageViews, s.reviews = pageViews, reviews
+	if reviews != nil {
+		moveReviews(reviews, nil)
+		store.Subscribe(func(ch *store.Change) { moveReviews(reviews, ch) })
+	}
+	ips, err := newClientIPs(site.Config.RateLimit)
+	if err != nil {
+		log.Fatalf("ratelimit: TrustedProxies: %s", err)
+	}
+	var limiter *rateLimiter
+	if site.Config.RateLimit != nil {
+		if limiter, err = newRateLimiter(site.Config.RateLimit, ratelimit.NewMemory(), net.DefaultResolver, ips); err != nil {
+			log.Fatalf("ratelimit: %s", err)
+		}
+	}
+	var access *metricsAccess
+	if site.Config.Metrics != nil {
+		if access, err = newMetricsAccess(site.Config.Metrics, ips); err != nil {
+			log.Fatalf("metrics: %s", err)
+		}
+	}
+	for _, cfg := range site.Sites {
+		ss := newSiteServer(cfg, calls, pageViews, reviews, s.metrics)
+		ss.limiter = limiter
+		ss.ips = ips
+		ss.metricsAccess = access
+		ss.health = s.health
+		ss.checkTemplates()
+		s.sites = append(s.sites, ss)
+	}
+	s.metrics.sites = s.sites
+	go store.WatchShifts()
+	go watchTemplates(dirs, 2*time.Second, func() {
+		for _, ss := range s.sites {
+			ss.checkTemplates()
+			ss.cache.Purge()
+		}
 	})
-	return &Server{port: &p, app: app}
+	return s
 }
 
-func (s *Server) set() {
-	s.app.Static("/static", "./static")
+func openPageViews() (*analytics.Recorder, error) {
+	if err := os.MkdirAll(site.Config.DataDir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	return analytics.Open(filepath.Join(site.Config.DataDir, "analytics.db"))
 }
 
-func (s *Server) middlewares() {
+func openReviews() (*review.DB, error) {
+	if err := os.MkdirAll(site.Config.DataDir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	return review.Open(filepath.Join(site.Config.DataDir, "reviews.db"))
+}
+
+// moveReviews: 가게 Key가 바뀌어도 후기가 따라가도록 StoreKey를 옮김.
+// Save로 지역, 업종, 상호를 바꾼 가게(ch.Renamed)와, 예전 주소를 새 주소로 보내는 가게(MovedStore)의 후기.
+// 서버 시작할 때는 ch 없이 호출
+func moveReviews(reviews *review.DB, ch *store.Change) {
+	rekey := func(oldKey, newKey string) {
+		n, err := reviews.Rekey(oldKey, newKey)
+		if err != nil {
+			log.Printf("review: %s => %s: %s", oldKey, newKey, err)
+		} else if n > 0 {
+			log.Printf("review: %s => %s: 후기 %d개를 옮겼습니다", oldKey, newKey, n)
+		}
+	}
+	if ch != nil {
+		for oldKey, newKey := range ch.Renamed {
+			rekey(oldKey, newKey)
+		}
+	}
+	keys, err := reviews.StoreKeys()
+	if err != nil {
+		log.Printf("review: %s", err)
+		return
+	}
+	catalog := store.NewCatalog(nil)
+	for _, key := range keys {
+		parts := strings.Split(key, ":")
+		if _, has := catalog.GetByKey(key); has || len(parts) != 5 {
+			continue
+		}
+		// 가게 페이지와 같은 순서. 삭제한 가게의 후기는 그대로 둠
+		if _, removed := store.GetRemoval(parts[0], parts[1], parts[2], parts[3], parts[4]); removed {
+			continue
+		}
+		if moved, has := catalog.MovedStore(parts[0], parts[3], parts[4]); has {
+			rekey(key, moved.Key())
+		}
+	}
+}
+
+// checkTemplates: 템플릿을 모두 파싱해서 /readyz에 결과 기록
+func (s *siteServer) checkTemplates() {
+	err := engine(s.site, nil).Load()
+	if err != nil {
+		log.Printf("server: %s: templates: %s", s.site.Domain, err)
+	}
+	s.health.setTemplates(s.site.Domain, err)
+}
+
+func (s *siteServer) set() {
+	// 정적 파일, 요청 제한으로 막은 요청도 기록
+	s.app.Use(s.metrics.middleware(s.site))
+	s.app.Use(logRequests(s.ips))
+	s.app.Use(recoverPanic)
+	// 요청 제한, 보안 헤더, 캐시 없이 응답
+	handleHealth(s.app, s.health)
+	if s.metricsAccess != nil {
+		s.app.Get("/metrics", s.metrics.handler(s.metricsAccess))
+	}
+	// 정적 파일에도 nosniff 등을 붙이기 위해 /static보다 먼저 설치
+	if s.site.Security != nil {
+		s.app.Use(securityHeaders(s.site.Security))
+	}
+	s.app.Static("/static", s.site.StaticDir, fiber.Static{
+		MaxAge: int(site.Config.StaticMaxAge.Seconds()),
+	})
+	if filepath.Clean(s.site.StaticDir) != "static" {
+		s.app.Static("/static", "./static", fiber.Static{
+			MaxAge: int(site.Config.StaticMaxAge.Seconds()),
+		})
+	}
+}
+
+func (s *siteServer) middlewares() {
+	// 렌더링, 캐시보다 먼저 막음
+	if s.limiter != nil {
+		s.app.Use(s.limiter.middleware)
+	}
+	// 렌더링 캐시, 조회수가 canonical 경로 하나로 모이도록 먼저 redirect
+	s.app.Use(canonicalURL(s.site))
 	s.app.Use("/",
+		recordViews(s.site, s.pageViews),
+		// 캐시 hit도 요청마다 nonce를 바꾼 뒤 압축. render_cache.go middleware 참고
 		compress.New(compress.Config{Level: compress.Level(2)}),
-		bindSiteConfig,
+		s.cache.middleware,
+		bindSite(s.site, s.catalog, s.reviews),
 	)
 }
 
-func (s *Server) routes() {
+func (s *siteServer) routes() {
+	handleAdmin(s.app.Group("/admin"), s.site, s.cache, s.calls, s.pageViews, s.reviews)
+	handleAuthor(s.app.Group("/author"))
+	handleCall(s.app.Group("/call"), s.calls)
+	handleCSPReport(s.app.Group(cspReportPath))
 	handleCategory(s.app.Group("/category"))
+	handleCompare(s.app.Group("/"))
+	handleOG(s.app.Group("/og"))
+	handleStation(s.app.Group("/station"))
 	handleStore(s.app.Group("/store"))
 	handleIndex(s.app.Group("/"))
+	s.app.Use(notFound)
+}
+
+// handler: Host 헤더에 맞는 사이트의 fiber app으로 요청 전달
+func (s *Server) handler() fasthttp.RequestHandler {
+	byHost := map[*site.Site]fasthttp.RequestHandler{}
+	for _, ss := range s.sites {
+		ss.set()
+		ss.middlewares()
+		ss.routes()
+		byHost[ss.site] = ss.app.Handler()
+	}
+	return func(ctx *fasthttp.RequestCtx) {
+		byHost[site.ByHost(string(ctx.Host()))](ctx)
+	}
 }
 
 func (s *Server) Run() error {
-	s.set()
-	s.middlewares()
-	s.routes()
-	return s.app.Listen(s.port.String())
+	s.server = &fasthttp.Server{
+		Handler: s.handler(),
+		Name:    site.Config.Domain,
+		// fasthttp 에러도 slog로 기록
+		Logger:       log.Default(),
+		ReadTimeout:  site.Config.ReadTimeout,
+		WriteTimeout: site.Config.WriteTimeout,
+		IdleTimeout:  site.Config.IdleTimeout,
+	}
+	log.Printf("server: listening on %s", s.port.String())
+	return s.server.ListenAndServe(s.port.String())
 }
 
-type Author struct {
-	Name        string
-	ProfilePath string
+// Shutdown: 새 연결을 받지 않고 처리중인 요청이 끝나길 ctx 만료까지 기다린 뒤
+// 조회수를 기록하고 DB를 닫음. 요청이 끝나지 않아도 DB는 닫음
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.health.shuttingDown.Store(true)
+	var err error
+	if s.server != nil {
+		err = s.server.ShutdownWithContext(ctx)
+	}
+	if s.pageViews != nil {
+		if cerr := s.pageViews.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	if s.reviews != nil {
+		if cerr := s.reviews.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
 }
 
 type PageConfig struct {
-	Path          string
-	Author        *Author
+	Path string
+	// Author: 글 작성자. 가게 페이지는 가게 작성자, 나머지는 사이트 기본 작성자
+	Author        *store.Author
 	Title         string
 	Description   string
 	Keywords      string
@@ -118,4 +399,6 @@ type PageConfig struct {
 	DatePublished time.Time
 	DateModified  time.Time
 	ThumbnailPath string
+	// OGImagePath: og:image, twitter:image 경로
+	OGImagePath string
 }
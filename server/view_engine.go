@@ -0,0 +1,47 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/gofiber/template/html/v2"
+)
+
+// viewEngine: html.Engine과 같지만 {{embed}}한 본문 템플릿의 에러를 반환.
+// html.Engine의 embed 함수는 error 하나만 반환해서 에러 메세지가 본문에 그대로 찍히고 200으로 응답됨
+type viewEngine struct {
+	*html.Engine
+}
+
+func (e *viewEngine) Render(out io.Writer, name string, binding interface{}, layout ...string) error {
+	if e.ShouldReload {
+		e.Loaded = false
+	}
+	if err := e.Load(); err != nil {
+		return err
+	}
+	tmpl := e.Templates.Lookup(name)
+	if tmpl == nil {
+		return fmt.Errorf("render: template %s does not exist", name)
+	}
+	if len(layout) == 0 || layout[0] == "" {
+		return tmpl.Execute(out, binding)
+	}
+	lay := e.Templates.Lookup(layout[0])
+	if lay == nil {
+		return fmt.Errorf("render: LayoutName %s does not exist", layout[0])
+	}
+	// 본문을 먼저 렌더링해서 에러가 나면 레이아웃을 쓰기 전에 반환
+	body := &bytes.Buffer{}
+	if err := tmpl.Execute(body, binding); err != nil {
+		return err
+	}
+	e.Mutex.Lock()
+	defer e.Mutex.Unlock()
+	lay.Funcs(map[string]interface{}{
+		e.LayoutName: func() template.HTML { return template.HTML(body.String()) },
+	})
+	return lay.Execute(out, binding)
+}
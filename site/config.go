@@ -1,11 +1,19 @@
 package site
 
 import (
+	"os"
 	"strings"
 	"time"
 )
 
-var Config *config
+// Config: 기본 사이트. Host가 등록되지 않은 요청과 서버 전체 설정(Port, 캐시 등)에 사용
+var Config *Site
+
+// Sites: 한 프로세스에서 서비스하는 모든 사이트. Host 헤더로 구분
+var Sites []*Site
+
+// BuildVersion: 빌드시 주입. ex) go build -ldflags "-X github.com/jeonghoikun/colagom.com/site.BuildVersion=$(git rev-parse --short HEAD)"
+var BuildVersion = "dev"
 
 type Keywords []string
 
@@ -15,9 +23,99 @@ type searchEngineConnection struct {
 	Google string
 }
 
-type config struct {
-	Port                   uint32
-	Domain                 string
+// Region: 사이트에서 보여줄 지역. 이름은 region 패키지 gazetteer의 Name. ex) {Do: "서울", Si: "강남구"}
+type Region struct {
+	Do string
+	Si string
+}
+
+// Limit: 토큰 버킷. 초당 Rate개씩 최대 Burst개까지 요청 가능
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// RouteLimit: Prefix로 시작하는 경로의 IP별 제한. ex) {Prefix: "/og", Limit: Limit{Rate: 1, Burst: 10}}
+type RouteLimit struct {
+	Prefix string
+	// Suffix: 빈 값이 아니면 이 값으로 끝나는 경로만. ex) /reviews
+	Suffix string
+	// Method: 빈 값이 아니면 이 method만. ex) POST
+	Method string
+	Limit
+}
+
+// RateLimit: IP별 요청 제한
+type RateLimit struct {
+	// Default: 모든 요청에 적용하는 IP별 제한
+	Default Limit
+	// Routes: 경로별로 Default와 함께 적용하는 제한. Prefix, Suffix가 가장 긴 규칙 하나만 적용
+	Routes []*RouteLimit
+	// Block: 항상 403으로 응답할 IP 또는 대역. ex) 203.0.113.7, 198.51.100.0/24
+	Block []string
+	// IPHeader: 프록시 뒤에서 실행할 때 클라이언트 IP가 담긴 헤더. ex) X-Forwarded-For. 빈 값이면 접속한 IP
+	IPHeader string
+	// TrustedProxies: IPHeader를 믿을 프록시 IP 또는 대역. 접속한 IP가 여기 없으면 헤더를 무시.
+	// 헤더 값은 오른쪽부터 이 목록에 없는 첫 IP를 클라이언트로 봄. ex) 127.0.0.1, 10.0.0.0/8
+	TrustedProxies []string
+	// VerifyBots: 역방향 DNS로 확인된 검색엔진 크롤러는 제한하지 않음
+	VerifyBots bool
+}
+
+// Log: 요청, 서버 로그 출력. JSON 한 줄씩 기록
+type Log struct {
+	// Path: 로그 파일. 빈 값이면 stderr
+	Path string
+	// MaxSizeMB: 파일이 이 크기를 넘으면 새 파일로 교체
+	MaxSizeMB int
+	// MaxBackups, MaxAgeDays: 보관할 이전 파일 수와 일수. 0이면 지우지 않음
+	MaxBackups int
+	MaxAgeDays int
+	// Level: debug, info, warn, error. /healthz, /readyz 요청 로그는 debug
+	Level string
+}
+
+// Metrics: /metrics 접근 허용. Allow의 IP이거나 Authorization: Bearer <Token>이면 허용.
+// RateLimit.IPHeader를 설정하면(프록시 뒤) Allow는 무시하고 토큰으로만 허용
+type Metrics struct {
+	// Allow: ex) []string{"127.0.0.1", "10.0.0.0/8"}
+	Allow []string
+	// Token: 빈 값이면 토큰으로는 접근할 수 없음
+	Token string
+}
+
+// Security: 모든 응답에 붙이는 보안 헤더
+type Security struct {
+	// HSTSMaxAge: Strict-Transport-Security max-age. 0이면 보내지 않음
+	HSTSMaxAge time.Duration
+	// ReferrerPolicy: ex) strict-origin-when-cross-origin
+	ReferrerPolicy string
+	// PermissionsPolicy: ex) camera=(), microphone=()
+	PermissionsPolicy string
+	// CSP: Content-Security-Policy 지시어. script-src에는 요청마다 만든 nonce,
+	// 마지막에는 report-uri /csp-report가 자동으로 붙음
+	CSP []string
+	// CSPReportOnly: 차단하지 않고 위반 보고만 받음(Content-Security-Policy-Report-Only)
+	CSPReportOnly bool
+}
+
+type Site struct {
+	Port   uint32
+	Domain string
+	// Scheme: canonical 주소의 scheme. 빈 값이면 https
+	Scheme string
+	// Aliases: Domain 외에 이 사이트로 연결할 Host. ex) www.colagom.com
+	// 별칭 등 Domain이 아닌 Host로 온 요청은 Domain으로 301 (개발 모드 제외)
+	Aliases []string
+	// Regions: 이 사이트의 카탈로그에 포함할 지역. 비어있으면 모든 가게
+	Regions []*Region
+	// StationRadius: 역 페이지(/station/:name)에 보여줄 가게까지의 도보 거리(m). 0이면 역 페이지 비활성화
+	StationRadius int
+	// ViewsDir: 사이트 전용 템플릿 디렉토리. 같은 이름의 템플릿이 ./views보다 우선
+	ViewsDir string
+	// StaticDir: 사이트 전용 /static 디렉토리. 없는 파일은 ./static에서 찾음
+	StaticDir string
+	// Author: 기본 작성자 Slug(store/author.go). 작성자를 지정하지 않은 가게와 메인, 카테고리 페이지에 사용
 	Author                 string
 	Title                  string
 	Description            string
@@ -26,27 +124,188 @@ type config struct {
 	DateModified           time.Time
 	PhoneNumber            string
 	SearchEngineConnection *searchEngineConnection
+	// OGFontPath: 공유 이미지(og:image) 생성에 쓰는 한글 폰트 파일. 없으면 썸네일로 대신하고 /readyz의 ogFont에 표시
+	OGFontPath string
+	// OGCacheDir: 생성한 공유 이미지를 저장하는 디렉토리
+	OGCacheDir string
+	// StaticMaxAge: /static 응답의 Cache-Control max-age
+	StaticMaxAge time.Duration
+	// RenderCacheMaxBytes: 렌더링된 페이지 캐시의 최대 크기
+	RenderCacheMaxBytes int
+	// AdminUser, AdminPassword: /admin 로그인 계정(basic auth도 가능). 비밀번호는 환경변수 COLAGOM_ADMIN_PASSWORD
+	// 비밀번호가 없으면 /admin 비활성화
+	AdminUser     string
+	AdminPassword string
+	// Dev: 개발 모드. 500 페이지에 템플릿 에러와 panic stack을 그대로 보여줌. 환경변수 COLAGOM_DEV=1
+	Dev bool
+	// ReadTimeout, WriteTimeout: 요청 읽기, 응답 쓰기 제한 시간. IdleTimeout: keep-alive 연결 유지 시간
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+	// ShutdownTimeout: SIGTERM, SIGINT를 받은 뒤 처리중인 요청을 기다리는 최대 시간
+	ShutdownTimeout time.Duration
+	// Log: 로그 출력. nil이면 stderr에 info 이상
+	Log *Log
+	// Metrics: Prometheus 지표. nil이면 /metrics 비활성화
+	Metrics *Metrics
+	// Security: 보안 헤더. nil이면 보내지 않음
+	Security *Security
+	// RateLimit: 서버 전체 IP별 요청 제한. nil이면 제한하지 않음
+	RateLimit *RateLimit
+	// DataDir: 서버에서 변경하는 데이터(전화번호 규칙, 변경 기록 등)를 저장하는 디렉토리
+	DataDir string
 }
 
 func date(year, month, day int) time.Time {
 	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.Local)
 }
 
-func Init() {
-	c := &config{}
+// Includes: 가게 지역이 이 사이트의 카탈로그에 포함되는지
+func (c *Site) Includes(do, si string) bool {
+	if len(c.Regions) == 0 {
+		return true
+	}
+	for _, r := range c.Regions {
+		if r.Do == do && (r.Si == "" || r.Si == si) {
+			return true
+		}
+	}
+	return false
+}
+
+// Hosts: Domain과 Aliases
+func (c *Site) Hosts() []string { return append([]string{c.Domain}, c.Aliases...) }
+
+// ByHost: Host 헤더(포트 포함 가능)에 해당하는 사이트. 없으면 기본 사이트
+func ByHost(host string) *Site {
+	host = HostName(host)
+	for _, c := range Sites {
+		for _, h := range c.Hosts() {
+			if h == host {
+				return c
+			}
+		}
+	}
+	return Config
+}
+
+// gangnam: colagom.com
+func gangnam() *Site {
+	c := &Site{}
 	c.Port = uint32(8019)
 	c.Domain = "colagom.com"
-	c.Author = "콜라곰"
+	c.Scheme = "https"
+	c.Aliases = []string{"www.colagom.com"}
+	// 신사역 서쪽 잠원동은 서초구
+	c.Regions = []*Region{{Do: "서울", Si: "강남구"}, {Do: "서울", Si: "서초구"}}
+	c.StationRadius = 1000
+	c.StaticDir = "./static"
+	c.Author = "colagom"
 	c.Title = "콜라곰의 강남유흥 여행"
 	c.Description = "콜라곰과 함께 떠나는 강남의 유흥주점의 가격, 시스템, 위치정보 안내. 가라오케, 셔츠룸, 하이퍼블릭, 레깅스룸, 쩜오, 호빠, 클럽의 모든 정보"
 	k := Keywords([]string{"콜라곰의 강남유흥 여행", "콜라곰", "강남유흥", "유흥", "유흥주점", "강남유흥주점", "강남룸빵", "룸빵", "가라오케", "셔츠룸", "하이퍼블릭", "레깅스룸", "쩜오", "호빠", "클럽"})
 	c.Keywords = &k
 	c.DatePublished = date(2023, 9, 6)
 	c.DateModified = date(2024, 2, 18)
-	// 업종마다 전화번호가 다른경우 store/store.go 파일의 setPhoneNumber 함수에서 하드코딩
+	// 기본 전화번호. 업종, 지역, 시간대별 번호는 store/contact.go의 규칙으로 지정
 	c.PhoneNumber = "010-6590-7589"
 	c.SearchEngineConnection = &searchEngineConnection{
 		Google: "_0O-P4S7tPNubMmy6jQikADwwAgFvJH5Ep0gWbFthYM",
 	}
-	Config = c
+	c.OGFontPath = "static/font/NanumGothic-Bold.ttf"
+	c.OGCacheDir = "cache/og"
+	c.StaticMaxAge = 30 * 24 * time.Hour
+	c.RenderCacheMaxBytes = 64 << 20
+	c.AdminUser = "admin"
+	c.AdminPassword = os.Getenv("COLAGOM_ADMIN_PASSWORD")
+	c.Dev = os.Getenv("COLAGOM_DEV") == "1"
+	c.ReadTimeout = 10 * time.Second
+	// 공유 이미지 생성이 오래 걸릴 수 있음
+	c.WriteTimeout = 30 * time.Second
+	c.IdleTimeout = 2 * time.Minute
+	c.ShutdownTimeout = 20 * time.Second
+	c.Log = &Log{
+		Path:       os.Getenv("COLAGOM_LOG_PATH"),
+		MaxSizeMB:  100,
+		MaxBackups: 10,
+		MaxAgeDays: 30,
+		Level:      "info",
+	}
+	c.Metrics = &Metrics{
+		Allow: []string{"127.0.0.1", "::1"},
+		Token: os.Getenv("COLAGOM_METRICS_TOKEN"),
+	}
+	c.Security = &Security{
+		HSTSMaxAge:        365 * 24 * time.Hour,
+		ReferrerPolicy:    "strict-origin-when-cross-origin",
+		PermissionsPolicy: "camera=(), microphone=(), geolocation=(), payment=(), usb=()",
+		CSP: []string{
+			"default-src 'self'",
+			// lottie-player. unpkg의 다른 패키지는 막도록 버전을 고정한 파일 주소만 허용.
+			// views/components/head/scripts.html의 src와 같아야 함
+			"script-src 'self' https://unpkg.com/@lottiefiles/lottie-player@2.0.8/dist/lottie-player.js",
+			// lottie 애니메이션 JSON
+			"connect-src 'self' https://lottie.host https://assets8.lottiefiles.com",
+			"img-src 'self' data: blob:",
+			// lottie-player가 넣는 style, 가게 본문의 style 속성
+			"style-src 'self' 'unsafe-inline'",
+			"font-src 'self'",
+			"frame-src https://www.google.com",
+			"frame-ancestors 'self'",
+			"form-action 'self'",
+			"base-uri 'self'",
+			"object-src 'none'",
+		},
+		// 위반 보고(/csp-report 로그)를 확인한 뒤 false로 바꿔 차단
+		CSPReportOnly: true,
+	}
+	c.RateLimit = &RateLimit{
+		Default: Limit{Rate: 5, Burst: 50},
+		Routes: []*RouteLimit{
+			// 공유 이미지 생성은 CPU를 많이 씀
+			{Prefix: "/og", Limit: Limit{Rate: 1, Burst: 10}},
+			{Prefix: "/admin/login", Limit: Limit{Rate: 0.1, Burst: 10}},
+			{Prefix: "/call", Limit: Limit{Rate: 0.5, Burst: 10}},
+			// 후기 작성. 검사 대기 목록이 한 IP의 글로 가득 차지 않도록 10분에 1개, 한번에 3개까지
+			{Prefix: "/store", Suffix: "/reviews", Method: "POST", Limit: Limit{Rate: 1.0 / 600, Burst: 3}},
+		},
+		Block:    []string{},
+		IPHeader: os.Getenv("COLAGOM_IP_HEADER"),
+		// 같은 서버의 reverse proxy. 다른 프록시를 쓰면 COLAGOM_TRUSTED_PROXIES=10.0.0.0/8,...
+		TrustedProxies: envList("COLAGOM_TRUSTED_PROXIES", []string{"127.0.0.1", "::1"}),
+		VerifyBots:     true,
+	}
+	c.DataDir = "data"
+	return c
+}
+
+// envList: 쉼표로 구분한 환경 변수 값. 없으면 def
+func envList(key string, def []string) []string {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	list := []string{}
+	for _, x := range strings.Split(v, ",") {
+		if x = strings.TrimSpace(x); x != "" {
+			list = append(list, x)
+		}
+	}
+	return list
+}
+
+// 사이트 추가: gangnam()처럼 설정 함수를 만들고 Sites에 추가. ex)
+//
+//	func busan() *Site {
+//		c := &Site{}
+//		c.Domain = "busan.example.com"
+//		c.Regions = []*Region{{Do: "부산"}}
+//		c.ViewsDir = "./sites/busan/views"
+//		c.StaticDir = "./sites/busan/static"
+//		...
+//		return c
+//	}
+func Init() {
+	Sites = []*Site{gangnam()}
+	Config = Sites[0]
 }
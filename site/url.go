@@ -0,0 +1,51 @@
+package site
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// CanonicalPath: 사이트 경로의 canonical 형태. 한글은 NFC로 정규화해서 퍼센트 인코딩, 끝의 /와 빈 segment는 제거,
+// 첫 segment(route 이름)는 소문자. query는 그대로 둠.
+// ex) /STORE/서울/강남구/ => /store/%EC%84%9C%EC%9A%B8/%EA%B0%95%EB%82%A8%EA%B5%AC
+func CanonicalPath(p string) string {
+	p, query, hasQuery := strings.Cut(p, "?")
+	segments := []string{}
+	for _, seg := range strings.Split(p, "/") {
+		if seg == "" {
+			continue
+		}
+		if v, err := url.PathUnescape(seg); err == nil {
+			seg = norm.NFC.String(v)
+		}
+		if len(segments) == 0 {
+			seg = strings.ToLower(seg)
+		}
+		segments = append(segments, url.PathEscape(seg))
+	}
+	p = "/" + strings.Join(segments, "/")
+	if hasQuery {
+		p += "?" + query
+	}
+	return p
+}
+
+// URL: 경로의 canonical 주소. ex) /author/colagom => https://colagom.com/author/colagom
+func (c *Site) URL(path string) string {
+	scheme := c.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Domain + CanonicalPath(path)
+}
+
+// HostName: Host 헤더에서 포트를 뺀 소문자 호스트. ex) WWW.colagom.com:443 => www.colagom.com
+func HostName(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.Trim(host, "[]"))
+}
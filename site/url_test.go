@@ -0,0 +1,76 @@
+package site
+
+import (
+	"testing"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestCanonicalPath(t *testing.T) {
+	nfd := norm.NFD.String("서울")
+	tests := []struct {
+		in, want string
+	}{
+		{"/", "/"},
+		{"", "/"},
+		{"//", "/"},
+		{"/store/", "/store"},
+		{"/STORE/서울/강남구/", "/store/%EC%84%9C%EC%9A%B8/%EA%B0%95%EB%82%A8%EA%B5%AC"},
+		{"/store/%EC%84%9C%EC%9A%B8", "/store/%EC%84%9C%EC%9A%B8"},
+		{"/store/%ec%84%9c%ec%9a%b8", "/store/%EC%84%9C%EC%9A%B8"},
+		{"/store/" + nfd, "/store/%EC%84%9C%EC%9A%B8"},
+		{"/Station/강남역", "/station/%EA%B0%95%EB%82%A8%EC%97%AD"},
+		// route 이름만 소문자
+		{"/author/Colagom", "/author/Colagom"},
+		{"/store//a///b", "/store/a/b"},
+		{"/compare?stores=a%2Cb", "/compare?stores=a%2Cb"},
+		{"/category/서울/?sort=rating", "/category/%EC%84%9C%EC%9A%B8?sort=rating"},
+		{"/a%2Fb", "/a%2Fb"},
+		{"/a b", "/a%20b"},
+	}
+	for _, tt := range tests {
+		if got := CanonicalPath(tt.in); got != tt.want {
+			t.Errorf("CanonicalPath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// canonicalURL 미들웨어는 CanonicalPath(p) != p이면 redirect하므로
+// 결과를 다시 넣으면 그대로 나와야 redirect가 반복되지 않음
+func TestCanonicalPathIsIdempotent(t *testing.T) {
+	inputs := []string{
+		"/", "/STORE/서울/강남구/", "/store/" + norm.NFD.String("강남구"), "/a%2Fb", "/a%2fb", "/%zz",
+		"/store/%E1%84%89", "/a+b", "/a;b", "/Ü/ü", "/store/a%20b/", "/compare?stores=x,y",
+		"/%25", "/a%25zz", "/~user", "/store/(주)", "/station/" + norm.NFKD.String("ﾊ"),
+	}
+	for _, in := range inputs {
+		once := CanonicalPath(in)
+		if twice := CanonicalPath(once); twice != once {
+			t.Errorf("CanonicalPath(%q) = %q, but CanonicalPath(%q) = %q", in, once, once, twice)
+		}
+	}
+}
+
+func TestSiteURL(t *testing.T) {
+	c := &Site{Domain: "colagom.com"}
+	if got, want := c.URL("/Author/colagom/"), "https://colagom.com/author/colagom"; got != want {
+		t.Errorf("URL = %q, want %q", got, want)
+	}
+	c.Scheme = "http"
+	if got, want := c.URL("/"), "http://colagom.com/"; got != want {
+		t.Errorf("URL = %q, want %q", got, want)
+	}
+}
+
+func TestHostName(t *testing.T) {
+	for in, want := range map[string]string{
+		"colagom.com":         "colagom.com",
+		"WWW.colagom.com:443": "www.colagom.com",
+		"[::1]:8019":          "::1",
+		"localhost:8019":      "localhost",
+	} {
+		if got := HostName(in); got != want {
+			t.Errorf("HostName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
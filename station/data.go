@@ -0,0 +1,31 @@
+package station
+
+// stations: 강남, 서초 지하철역 하드코딩. 좌표는 역 중심(WGS84).
+// 가게가 다른 지역에 생기면 그 지역 역을 추가
+var stations = []*Station{
+	{Name: "강남역", Lines: []string{"2호선", "신분당선"}, English: "Gangnam", Latitude: 37.497942, Longitude: 127.027621},
+	{Name: "역삼역", Lines: []string{"2호선"}, English: "Yeoksam", Latitude: 37.500622, Longitude: 127.036456},
+	{Name: "선릉역", Lines: []string{"2호선", "수인분당선"}, English: "Seolleung", Latitude: 37.504503, Longitude: 127.049008},
+	{Name: "삼성역", Lines: []string{"2호선"}, English: "Samseong", Latitude: 37.508844, Longitude: 127.063160},
+	{Name: "종합운동장역", Lines: []string{"2호선", "9호선"}, English: "Sports Complex", Latitude: 37.510997, Longitude: 127.073642},
+	{Name: "교대역", Lines: []string{"2호선", "3호선"}, English: "Seoul Nat'l Univ. of Education", Latitude: 37.493415, Longitude: 127.014080},
+	{Name: "신논현역", Lines: []string{"9호선", "신분당선"}, English: "Sinnonhyeon", Latitude: 37.504598, Longitude: 127.025060},
+	{Name: "언주역", Lines: []string{"9호선"}, English: "Eonju", Latitude: 37.507287, Longitude: 127.033868},
+	{Name: "선정릉역", Lines: []string{"9호선", "수인분당선"}, English: "Seonjeongneung", Latitude: 37.510297, Longitude: 127.043999},
+	{Name: "삼성중앙역", Lines: []string{"9호선"}, English: "Samseongjungang", Latitude: 37.513011, Longitude: 127.053282},
+	{Name: "봉은사역", Lines: []string{"9호선"}, English: "Bongeunsa", Latitude: 37.514219, Longitude: 127.060245},
+	{Name: "고속터미널역", Lines: []string{"3호선", "7호선", "9호선"}, English: "Express Bus Terminal", Latitude: 37.504810, Longitude: 127.004943},
+	{Name: "신사역", Lines: []string{"3호선", "신분당선"}, English: "Sinsa", Latitude: 37.516334, Longitude: 127.020114},
+	{Name: "잠원역", Lines: []string{"3호선"}, English: "Jamwon", Latitude: 37.512759, Longitude: 127.011220},
+	{Name: "압구정역", Lines: []string{"3호선"}, English: "Apgujeong", Latitude: 37.527072, Longitude: 127.028461},
+	{Name: "양재역", Lines: []string{"3호선", "신분당선"}, English: "Yangjae", Latitude: 37.484147, Longitude: 127.034631},
+	{Name: "매봉역", Lines: []string{"3호선"}, English: "Maebong", Latitude: 37.486947, Longitude: 127.046769},
+	{Name: "도곡역", Lines: []string{"3호선", "수인분당선"}, English: "Dogok", Latitude: 37.490922, Longitude: 127.055452},
+	{Name: "대치역", Lines: []string{"3호선"}, English: "Daechi", Latitude: 37.494612, Longitude: 127.063642},
+	{Name: "논현역", Lines: []string{"7호선", "신분당선"}, English: "Nonhyeon", Latitude: 37.511093, Longitude: 127.021415},
+	{Name: "학동역", Lines: []string{"7호선"}, English: "Hakdong", Latitude: 37.514229, Longitude: 127.031656},
+	{Name: "강남구청역", Lines: []string{"7호선", "수인분당선"}, English: "Gangnam-gu Office", Latitude: 37.517186, Longitude: 127.041255},
+	{Name: "청담역", Lines: []string{"7호선"}, English: "Cheongdam", Latitude: 37.519365, Longitude: 127.051870},
+	{Name: "압구정로데오역", Lines: []string{"수인분당선"}, English: "Apgujeongrodeo", Latitude: 37.527381, Longitude: 127.040534},
+	{Name: "한티역", Lines: []string{"수인분당선"}, English: "Hanti", Latitude: 37.496237, Longitude: 127.052873},
+}
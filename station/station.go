@@ -0,0 +1,88 @@
+package station
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// Station: 지하철역
+type Station struct {
+	// Name: ex) 강남역
+	Name string
+	// Lines: 환승역이면 여러 노선. ex) 2호선, 신분당선
+	Lines []string
+	// English: ex) Gangnam
+	English   string
+	Latitude  float64
+	Longitude float64
+}
+
+// Nearby: 가게에서 가까운 역
+type Nearby struct {
+	Station *Station
+	// Meters: 걸어서 가는 거리. 직선거리에 detour를 곱한 값
+	Meters int
+	// Minutes: 도보 시간
+	Minutes int
+}
+
+const (
+	earthRadius = 6371000.0
+	// detour: 직선거리를 실제 걷는 길 거리로 바꾸는 비율. 강남 블록 기준
+	detour = 1.3
+	// walkSpeed: 분당 걷는 거리(m). 시속 4km
+	walkSpeed = 67.0
+	// MaxMeters: 이보다 멀면 가까운 역으로 보지 않음
+	MaxMeters = 1500
+)
+
+// Distance: 두 좌표의 직선거리(m)
+func Distance(lat1, lng1, lat2, lng2 float64) float64 {
+	rad := func(d float64) float64 { return d * math.Pi / 180 }
+	dLat, dLng := rad(lat2-lat1), rad(lng2-lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(rad(lat1))*math.Cos(rad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
+
+// Walk: 좌표에서 역까지 걸어서 가는 거리와 시간
+func (s *Station) Walk(lat, lng float64) *Nearby {
+	m := int(math.Round(Distance(lat, lng, s.Latitude, s.Longitude) * detour))
+	return &Nearby{Station: s, Meters: m, Minutes: WalkMinutes(m)}
+}
+
+// WalkMinutes: 도보 거리(m)를 걷는 시간(분). 올림
+func WalkMinutes(meters int) int { return int(math.Ceil(float64(meters) / walkSpeed)) }
+
+// Nearest: 좌표에서 걸어서 MaxMeters 안에 있는 역 n개. 가까운 역부터
+func Nearest(lat, lng float64, n int) []*Nearby {
+	list := []*Nearby{}
+	for _, s := range stations {
+		if w := s.Walk(lat, lng); w.Meters <= MaxMeters {
+			list = append(list, w)
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Meters < list[j].Meters })
+	if len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
+
+// Path: 역 페이지 경로. ex) /station/강남역
+func (s *Station) Path() string { return "/station/" + s.Name }
+
+// LineNames: ex) 2호선, 신분당선
+func (s *Station) LineNames() string { return strings.Join(s.Lines, ", ") }
+
+func List() []*Station { return stations }
+
+func Get(name string) (*Station, bool) {
+	for _, s := range stations {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return nil, false
+}
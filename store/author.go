@@ -0,0 +1,106 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jeonghoikun/colagom.com/site"
+)
+
+type AuthorLink struct {
+	// Name: ex) 인스타그램
+	Name string
+	// URL: ex) https://www.instagram.com/colagom
+	URL string
+}
+
+// Author: 가게 글 작성자. 가게의 Author에 Slug를 입력
+type Author struct {
+	// Slug: 작성자 페이지 경로. ex) colagom => /author/colagom
+	Slug string
+	// Name: ex) 콜라곰
+	Name string
+	// Bio: 작성자 소개
+	Bio string
+	// PhotoPath: 프로필 사진. ex) /static/img/site/author/profile.png
+	PhotoPath string
+	// Email: 문의 이메일. 없으면 빈 값
+	Email string
+	// Links: SNS 등 작성자의 다른 프로필. JSON-LD sameAs에 사용
+	Links []*AuthorLink
+}
+
+// Path: ex) /author/colagom
+func (a *Author) Path() string { return "/author/" + a.Slug }
+
+// authors: 작성자 하드코딩. 추가하면 가게의 Author, 사이트 설정의 Author에 Slug로 지정
+var authors = []*Author{
+	{
+		Slug:      "colagom",
+		Name:      "콜라곰",
+		Bio:       "강남 유흥주점을 직접 다니며 가격, 시스템, 영업시간을 정리하는 콜라곰 실장입니다.",
+		PhotoPath: "/static/img/site/author/profile.png",
+		Links:     []*AuthorLink{},
+	},
+}
+
+func ListAuthors() []*Author { return authors }
+
+func GetAuthor(slug string) (*Author, bool) {
+	for _, a := range authors {
+		if a.Slug == slug {
+			return a, true
+		}
+	}
+	return nil, false
+}
+
+// SiteAuthor: 사이트 기본 작성자. 작성자를 지정하지 않은 가게와 메인, 카테고리 페이지에 사용
+func SiteAuthor(cfg *site.Site) *Author {
+	a, _ := GetAuthor(cfg.Author)
+	return a
+}
+
+// AuthorOf: 가게 작성자. 지정하지 않았으면 사이트 기본 작성자
+func AuthorOf(cfg *site.Site, s *Store) *Author {
+	if a, has := GetAuthor(s.Author); has {
+		return a
+	}
+	return SiteAuthor(cfg)
+}
+
+// ListStoresByAuthor: 작성자가 쓴 가게 목록
+func (c *Catalog) ListStoresByAuthor(cfg *site.Site, a *Author) []*Store {
+	list := []*Store{}
+	for _, s := range c.ListAllStores() {
+		if AuthorOf(cfg, s) == a {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// validateAuthors: Slug 중복, 사이트 기본 작성자가 없는 경우 에러
+func validateAuthors() error {
+	problems := []string{}
+	seen := map[string]bool{}
+	for _, a := range authors {
+		if err := pathName("Author.Slug", a.Slug); err != nil {
+			problems = append(problems, err.Error())
+		}
+		if seen[a.Slug] {
+			problems = append(problems, fmt.Sprintf("%s: 같은 작성자가 이미 있습니다", a.Slug))
+		}
+		seen[a.Slug] = true
+	}
+	for _, cfg := range site.Sites {
+		if _, has := GetAuthor(cfg.Author); !has {
+			problems = append(problems, fmt.Sprintf("%s: 알 수 없는 작성자입니다: %q", cfg.Domain, cfg.Author))
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package store
+
+// Catalog: 사이트별로 보여줄 가게만 고른 카탈로그
+type Catalog struct {
+	include func(do, si string) bool
+}
+
+// NewCatalog: include가 true인 지역의 가게만 포함. nil이면 모든 가게
+func NewCatalog(include func(do, si string) bool) *Catalog {
+	return &Catalog{include: include}
+}
+
+func (c *Catalog) ListAllStores() []*Store {
+	all := ListAllStores()
+	if c.include == nil {
+		return all
+	}
+	list := []*Store{}
+	for _, s := range all {
+		if c.include(s.Location.Do, s.Location.Si) {
+			list = append(list, s)
+		}
+	}
+	return list
+}
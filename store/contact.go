@@ -0,0 +1,439 @@
+package store
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeonghoikun/colagom.com/site"
+)
+
+// ContactRule: 전화번호 배정 규칙. 조건 필드가 비어있으면 모든 값에 해당
+type ContactRule struct {
+	ID string `json:"id"`
+	// StoreKey: 특정 가게. ex) 서울:강남구:역삼동:쩜오:에프원
+	StoreKey string `json:"storeKey,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Do       string `json:"do,omitempty"`
+	Si       string `json:"si,omitempty"`
+	Dong     string `json:"dong,omitempty"`
+	// From, To: 시간대(KST). ex) 02:00~10:00. 22:00~06:00 처럼 자정을 넘어갈 수 있음. 비어있으면 하루종일
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+	// Priority: 여러 규칙이 맞으면 Priority가 높은 규칙, 같으면 조건이 더 구체적인 규칙 사용
+	Priority    int    `json:"priority,omitempty"`
+	PhoneNumber string `json:"phoneNumber"`
+	Note        string `json:"note,omitempty"`
+}
+
+// ContactTarget: 전화번호를 찾을 대상. 가게 페이지가 아니면 StoreKey 등이 빈 값
+type ContactTarget struct {
+	StoreKey string
+	Type     string
+	Do       string
+	Si       string
+	Dong     string
+}
+
+// ContactAudit: 규칙 변경 기록. contact_audit.jsonl에 한줄씩 추가
+type ContactAudit struct {
+	Time   time.Time    `json:"time"`
+	Actor  string       `json:"actor"`
+	Action string       `json:"action"`
+	Before *ContactRule `json:"before,omitempty"`
+	After  *ContactRule `json:"after,omitempty"`
+}
+
+var ErrContactRuleNotFound = errors.New("contact rule not found")
+
+var (
+	contactMu       sync.RWMutex
+	contactRules    []*ContactRule
+	contactRevision int
+	// shiftsChanged: 규칙이 바뀌면 WatchShifts가 다음 경계를 다시 계산하도록 깨움
+	shiftsChanged = make(chan struct{}, 1)
+)
+
+func wakeShiftWatcher() {
+	select {
+	case shiftsChanged <- struct{}{}:
+	default:
+	}
+}
+
+// defaultContactRules: 규칙 파일이 없을 때 사용. 업종별 담당 실장 번호
+func defaultContactRules() []*ContactRule {
+	return []*ContactRule{
+		{ID: "type-dot5", Type: STORE_TYPE_DOT5, PhoneNumber: "010-2170-4981"},
+		{ID: "type-club", Type: STORE_TYPE_CLUB, PhoneNumber: "010-6590-7589"},
+		{ID: "type-hobba", Type: STORE_TYPE_HOBBA, PhoneNumber: "010-6590-7589"},
+	}
+}
+
+func contactRulesPath() string { return filepath.Join(site.Config.DataDir, "contact_rules.json") }
+
+func contactAuditPath() string { return filepath.Join(site.Config.DataDir, "contact_audit.jsonl") }
+
+func loadContactRules() error {
+	contactMu.Lock()
+	defer contactMu.Unlock()
+	b, err := os.ReadFile(contactRulesPath())
+	if os.IsNotExist(err) {
+		contactRules = defaultContactRules()
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	list := []*ContactRule{}
+	if err := json.Unmarshal(b, &list); err != nil {
+		return fmt.Errorf("%s: %w", contactRulesPath(), err)
+	}
+	for _, r := range list {
+		if err := r.validate(); err != nil {
+			return fmt.Errorf("%s: %w", contactRulesPath(), err)
+		}
+	}
+	contactRules = list
+	return nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("시간 형식은 HH:MM: %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func (r *ContactRule) validate() error {
+	if r.ID == "" {
+		return errors.New("id가 없습니다")
+	}
+	if r.PhoneNumber == "" {
+		return fmt.Errorf("%s: phoneNumber가 없습니다", r.ID)
+	}
+	if (r.From == "") != (r.To == "") {
+		return fmt.Errorf("%s: from, to는 함께 입력해야 합니다", r.ID)
+	}
+	if r.From != "" {
+		if _, err := parseClock(r.From); err != nil {
+			return fmt.Errorf("%s: %w", r.ID, err)
+		}
+		if _, err := parseClock(r.To); err != nil {
+			return fmt.Errorf("%s: %w", r.ID, err)
+		}
+	}
+	return nil
+}
+
+func (r *ContactRule) inShift(t time.Time) bool {
+	if r.From == "" {
+		return true
+	}
+	from, _ := parseClock(r.From)
+	to, _ := parseClock(r.To)
+	now := t.Hour()*60 + t.Minute()
+	if from <= to {
+		return from <= now && now < to
+	}
+	return now >= from || now < to
+}
+
+func (r *ContactRule) matches(target *ContactTarget, t time.Time) bool {
+	conds := [][2]string{
+		{r.StoreKey, target.StoreKey},
+		{r.Type, target.Type},
+		{r.Do, target.Do},
+		{r.Si, target.Si},
+		{r.Dong, target.Dong},
+	}
+	for _, c := range conds {
+		if c[0] != "" && c[0] != c[1] {
+			return false
+		}
+	}
+	return r.inShift(t)
+}
+
+// specificity: 가게 > 시간대 > 동 > 업종 > 시 > 도
+func (r *ContactRule) specificity() int {
+	n := 0
+	for i, v := range []string{r.Do, r.Si, r.Type, r.Dong, r.From, r.StoreKey} {
+		if v != "" {
+			n += 1 << i
+		}
+	}
+	return n
+}
+
+// ResolvePhoneNumber: 시간 t에 target에 연결할 전화번호. 맞는 규칙이 없으면 fallback
+func ResolvePhoneNumber(target *ContactTarget, t time.Time, fallback string) string {
+	contactMu.RLock()
+	defer contactMu.RUnlock()
+	var best *ContactRule
+	for _, r := range contactRules {
+		if !r.matches(target, t) {
+			continue
+		}
+		if best == nil || r.Priority > best.Priority ||
+			(r.Priority == best.Priority && r.specificity() > best.specificity()) {
+			best = r
+		}
+	}
+	if best == nil {
+		return fallback
+	}
+	return best.PhoneNumber
+}
+
+// ContactTarget: 가게 페이지의 전화번호 규칙 대상
+func (s *Store) ContactTarget() *ContactTarget {
+	return &ContactTarget{
+		StoreKey: s.Key(),
+		Type:     s.Type,
+		Do:       s.Location.Do,
+		Si:       s.Location.Si,
+		Dong:     s.Location.Dong,
+	}
+}
+
+// PhoneNumber: 시간 t에 이 가게에 연결할 전화번호. 맞는 규칙이 없으면 fallback(사이트 전화번호)
+func (s *Store) PhoneNumber(t time.Time, fallback string) string {
+	return ResolvePhoneNumber(s.ContactTarget(), t, fallback)
+}
+
+// ContactVersion: 규칙이 바뀌거나 시간대가 바뀌면 달라지는 값. HTTP ETag에 사용
+func ContactVersion(t time.Time) string {
+	contactMu.RLock()
+	defer contactMu.RUnlock()
+	h := sha1.New()
+	fmt.Fprintf(h, "%d", contactRevision)
+	for _, r := range contactRules {
+		if r.inShift(t) {
+			h.Write([]byte(r.ID))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))[:8]
+}
+
+func ListContactRules() []*ContactRule {
+	contactMu.RLock()
+	defer contactMu.RUnlock()
+	list := []*ContactRule{}
+	for _, r := range contactRules {
+		x := *r
+		list = append(list, &x)
+	}
+	return list
+}
+
+// saveContactRules: contactMu.Lock 상태에서 호출
+func saveContactRules() error {
+	b, err := json.MarshalIndent(contactRules, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(site.Config.DataDir, os.ModePerm); err != nil {
+		return err
+	}
+	tmp := contactRulesPath() + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, contactRulesPath())
+}
+
+func appendContactAudit(a *ContactAudit) error {
+	if err := os.MkdirAll(site.Config.DataDir, os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(contactAuditPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	b, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+// changeContactRules: 규칙 목록을 바꾸고 저장, 감사 기록 추가 후 구독자에게 알림.
+// apply는 contactMu를 잡은 상태에서 호출하고, 바꾸기 전 규칙(before)을 반환. 추가면 nil
+func changeContactRules(actor, action string, after *ContactRule, apply func() (*ContactRule, error)) error {
+	contactMu.Lock()
+	prev := append([]*ContactRule{}, contactRules...)
+	before, err := apply()
+	if err != nil {
+		contactMu.Unlock()
+		return err
+	}
+	if err := saveContactRules(); err != nil {
+		contactRules = prev
+		contactMu.Unlock()
+		return err
+	}
+	contactRevision++
+	contactMu.Unlock()
+	wakeShiftWatcher()
+	notifyContacts()
+	// 규칙은 이미 바뀌었으므로 기록 실패는 에러로 반환하지 않음
+	if err := appendContactAudit(&ContactAudit{
+		Time: time.Now(), Actor: actor, Action: action, Before: before, After: after,
+	}); err != nil {
+		log.Printf("store: %s 기록 실패: %s", contactAuditPath(), err)
+	}
+	return nil
+}
+
+func findContactRule(id string) int {
+	for i, r := range contactRules {
+		if r.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func AddContactRule(r *ContactRule, actor string) error {
+	if err := r.validate(); err != nil {
+		return err
+	}
+	return changeContactRules(actor, "add", r, func() (*ContactRule, error) {
+		if findContactRule(r.ID) != -1 {
+			return nil, fmt.Errorf("%s: 이미 있는 id입니다", r.ID)
+		}
+		contactRules = append(contactRules, r)
+		return nil, nil
+	})
+}
+
+func UpdateContactRule(r *ContactRule, actor string) error {
+	if err := r.validate(); err != nil {
+		return err
+	}
+	return changeContactRules(actor, "update", r, func() (*ContactRule, error) {
+		i := findContactRule(r.ID)
+		if i == -1 {
+			return nil, ErrContactRuleNotFound
+		}
+		before := contactRules[i]
+		list := append([]*ContactRule{}, contactRules...)
+		list[i] = r
+		contactRules = list
+		return before, nil
+	})
+}
+
+func DeleteContactRule(id, actor string) error {
+	return changeContactRules(actor, "delete", nil, func() (*ContactRule, error) {
+		i := findContactRule(id)
+		if i == -1 {
+			return nil, ErrContactRuleNotFound
+		}
+		before := contactRules[i]
+		list := append([]*ContactRule{}, contactRules[:i]...)
+		contactRules = append(list, contactRules[i+1:]...)
+		return before, nil
+	})
+}
+
+// ListContactAudit: 감사 기록 전체. 최신 기록이 먼저
+func ListContactAudit() ([]*ContactAudit, error) {
+	b, err := os.ReadFile(contactAuditPath())
+	if os.IsNotExist(err) {
+		return []*ContactAudit{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	list := []*ContactAudit{}
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		if line == "" {
+			continue
+		}
+		a := &ContactAudit{}
+		if err := json.Unmarshal([]byte(line), a); err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Time.After(list[j].Time) })
+	return list, nil
+}
+
+var (
+	contactSubscribersMu sync.Mutex
+	contactSubscribers   []func()
+)
+
+// SubscribeContacts: 규칙이 바뀌거나 시간대 경계(ex. 02:00)를 지날 때마다 fn 호출
+func SubscribeContacts(fn func()) {
+	contactSubscribersMu.Lock()
+	defer contactSubscribersMu.Unlock()
+	contactSubscribers = append(contactSubscribers, fn)
+}
+
+func notifyContacts() {
+	contactSubscribersMu.Lock()
+	list := append([]func(){}, contactSubscribers...)
+	contactSubscribersMu.Unlock()
+	for _, fn := range list {
+		fn()
+	}
+}
+
+// nextShiftBoundary: t 이후 가장 가까운 규칙 시간대 경계
+func nextShiftBoundary(t time.Time) time.Time {
+	contactMu.RLock()
+	defer contactMu.RUnlock()
+	next := t.Add(24 * time.Hour)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	for _, r := range contactRules {
+		if r.From == "" {
+			continue
+		}
+		for _, clock := range []string{r.From, r.To} {
+			m, _ := parseClock(clock)
+			b := day.Add(time.Duration(m) * time.Minute)
+			if !b.After(t) {
+				b = b.AddDate(0, 0, 1)
+			}
+			if b.Before(next) {
+				next = b
+			}
+		}
+	}
+	return next
+}
+
+// WatchShifts: 시간대 경계마다 구독자에게 알림. 서버 시작시 goroutine으로 실행.
+// 관리자 화면에서 규칙을 바꾸면 다음 경계를 다시 계산
+func WatchShifts() {
+	for {
+		now := time.Now()
+		timer := time.NewTimer(nextShiftBoundary(now).Sub(now) + time.Second)
+		select {
+		case <-timer.C:
+			notifyContacts()
+		case <-shiftsChanged:
+			timer.Stop()
+		}
+	}
+}
+
+// NewContactRuleID: 관리자 화면에서 id 없이 추가할 때 사용
+func NewContactRuleID() string { return "rule-" + strconv.FormatInt(time.Now().UnixNano(), 36) }
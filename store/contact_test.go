@@ -0,0 +1,67 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+var kst = time.FixedZone("KST", 9*60*60)
+
+func at(day, hour, minute int) time.Time { return time.Date(2024, 3, day, hour, minute, 0, 0, kst) }
+
+func TestContactRuleInShift(t *testing.T) {
+	tests := []struct {
+		from, to string
+		t        time.Time
+		want     bool
+	}{
+		{"", "", at(1, 3, 0), true},
+		{"02:00", "10:00", at(1, 1, 59), false},
+		{"02:00", "10:00", at(1, 2, 0), true},
+		{"02:00", "10:00", at(1, 9, 59), true},
+		{"02:00", "10:00", at(1, 10, 0), false},
+		// 자정을 넘어가는 시간대
+		{"22:00", "06:00", at(1, 21, 59), false},
+		{"22:00", "06:00", at(1, 22, 0), true},
+		{"22:00", "06:00", at(1, 0, 0), true},
+		{"22:00", "06:00", at(1, 5, 59), true},
+		{"22:00", "06:00", at(1, 6, 0), false},
+		{"00:00", "23:59", at(1, 23, 59), false},
+	}
+	for _, tt := range tests {
+		r := &ContactRule{ID: "r", From: tt.from, To: tt.to}
+		if got := r.inShift(tt.t); got != tt.want {
+			t.Errorf("%s~%s inShift(%s) = %v, want %v", tt.from, tt.to, tt.t.Format("15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestNextShiftBoundary(t *testing.T) {
+	prev := contactRules
+	defer func() { contactRules = prev }()
+	tests := []struct {
+		name  string
+		rules []*ContactRule
+		t     time.Time
+		want  time.Time
+	}{
+		{"no shift rules", []*ContactRule{{ID: "all"}}, at(1, 12, 0), at(2, 12, 0)},
+		{"next from", []*ContactRule{{ID: "a", From: "22:00", To: "06:00"}}, at(1, 12, 0), at(1, 22, 0)},
+		{"next to", []*ContactRule{{ID: "a", From: "22:00", To: "06:00"}}, at(1, 23, 0), at(2, 6, 0)},
+		{"after midnight", []*ContactRule{{ID: "a", From: "22:00", To: "06:00"}}, at(2, 1, 0), at(2, 6, 0)},
+		// 경계 시각 자체는 지난 것으로 보고 다음 경계
+		{"exactly on boundary", []*ContactRule{{ID: "a", From: "22:00", To: "06:00"}}, at(1, 22, 0), at(2, 6, 0)},
+		{"earliest of many", []*ContactRule{
+			{ID: "a", From: "22:00", To: "06:00"},
+			{ID: "b", From: "13:30", To: "14:00"},
+			{ID: "all"},
+		}, at(1, 12, 0), at(1, 13, 30)},
+	}
+	for _, tt := range tests {
+		contactRules = tt.rules
+		if got := nextShiftBoundary(tt.t); !got.Equal(tt.want) {
+			t.Errorf("%s: nextShiftBoundary(%s) = %s, want %s", tt.name, tt.t.Format("01-02 15:04"),
+				got.Format("01-02 15:04"), tt.want.Format("01-02 15:04"))
+		}
+	}
+}
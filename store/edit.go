@@ -0,0 +1,372 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeonghoikun/colagom.com/site"
+)
+
+var ErrStoreNotFound = errors.New("store not found")
+
+// storeEdit: 관리자 화면에서 수정한 가게. stores.json에 저장하고 카탈로그를 만들 때
+// 코드에 있는 가게 위에 덮어씀
+type storeEdit struct {
+	// Key: 덮어쓸 코드상의 가게 키. 관리자 화면에서 새로 만든 가게면 빈 값
+	Key   string `json:"key,omitempty"`
+	Store *Store `json:"store"`
+}
+
+// editsMu: stores.json 읽고 쓰기와 Reload를 한번에 하나씩
+var editsMu sync.Mutex
+
+func storeEditsPath() string { return filepath.Join(site.Config.DataDir, "stores.json") }
+
+func readStoreEdits() ([]*storeEdit, error) {
+	b, err := os.ReadFile(storeEditsPath())
+	if os.IsNotExist(err) {
+		return []*storeEdit{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	list := []*storeEdit{}
+	if err := json.Unmarshal(b, &list); err != nil {
+		return nil, fmt.Errorf("%s: %w", storeEditsPath(), err)
+	}
+	return list, nil
+}
+
+func writeStoreEdits(list []*storeEdit) error {
+	b, err := json.MarshalIndent(list, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(site.Config.DataDir, os.ModePerm); err != nil {
+		return err
+	}
+	tmp := storeEditsPath() + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, storeEditsPath())
+}
+
+// applyStoreEdits: 코드로 만든 stores에 stores.json 내용 반영. load에서 호출
+func applyStoreEdits() error {
+	edits, err := readStoreEdits()
+	if err != nil {
+		return err
+	}
+	for _, e := range edits {
+		if e.Store == nil {
+			continue
+		}
+		resolveLocation(e.Store.Location)
+		if e.Key == "" {
+			stores = append(stores, e.Store)
+			continue
+		}
+		replaced := false
+		for i, s := range stores {
+			if NFC(s.Key()) == NFC(e.Key) {
+				// 본문은 stores.json에 저장하지 않으므로 원래 가게의 본문을 그대로 사용
+				e.Store.BodyHTML, e.Store.source = s.BodyHTML, s.source
+				stores[i] = e.Store
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			log.Printf("store: warning: %s: 코드에 없는 가게의 수정 내용은 무시합니다", e.Key)
+		}
+	}
+	return nil
+}
+
+func today() time.Time {
+	now := time.Now()
+	return storeDate(now.Year(), int(now.Month()), now.Day())
+}
+
+// renameStoreFiles: 지역, 업종, 상호가 바뀌면 본문 템플릿과 이미지 디렉토리도 옮김.
+// 디스크의 이름이 NFD일 수 있으므로 옮길 파일과 옮길 위치 모두 resolveNFC로 찾음
+func renameStoreFiles(old, s *Store) error {
+	if old.Key() == s.Key() {
+		return nil
+	}
+	moves := [][2]string{
+		{resolveNFC(old.viewPath()), resolveNFC(s.viewPath())},
+		{resolveNFC(old.imageDir()), resolveNFC(s.imageDir())},
+	}
+	for _, m := range moves {
+		if _, err := os.Stat(m[0]); os.IsNotExist(err) {
+			continue
+		}
+		if _, err := os.Stat(m[1]); err == nil {
+			return fmt.Errorf("%s: 이미 있는 파일입니다", m[1])
+		}
+		if err := os.MkdirAll(filepath.Dir(m[1]), os.ModePerm); err != nil {
+			return err
+		}
+		if err := os.Rename(m[0], m[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Save: key 가게를 s로 바꿈. key가 빈 값이면 새 가게 추가. Markdown 가게는 .md 파일의 front matter에 저장.
+// 카탈로그를 다시 만들어 검사에 실패하면 저장하지 않고 에러 반환
+func Save(key string, s *Store) (ch *Change, err error) {
+	editsMu.Lock()
+	defer editsMu.Unlock()
+	edits, err := readStoreEdits()
+	if err != nil {
+		return nil, err
+	}
+	prev := append([]*storeEdit{}, edits...)
+	// 파일을 옮기기 전에 지역 이름을 gazetteer 이름으로 맞춤
+	resolveLocation(s.Location)
+	s.Gallery, s.Keywords, s.Stations = nil, nil, nil
+	s.DateModified = today()
+
+	if key == "" {
+		s.DatePublished = s.DateModified
+		edits = append(edits, &storeEdit{Store: s})
+	} else {
+		old, has := FindStore(key)
+		if !has {
+			return nil, ErrStoreNotFound
+		}
+		s.DatePublished = old.DatePublished
+		if old.IsMarkdown() {
+			return saveMarkdown(old, s, edits)
+		}
+		if err := renameStoreFiles(old, s); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err != nil {
+				renameStoreFiles(s, old)
+			}
+		}()
+		found := false
+		for i, e := range edits {
+			if e.Store != nil && e.Store.Key() == key {
+				edits[i] = &storeEdit{Key: e.Key, Store: s}
+				found = true
+				break
+			}
+		}
+		if !found {
+			edits = append(edits, &storeEdit{Key: key, Store: s})
+		}
+	}
+
+	if err = writeStoreEdits(edits); err != nil {
+		return nil, err
+	}
+	if ch, err = reload(renamedKeys(key, s)); err != nil {
+		if werr := writeStoreEdits(prev); werr != nil {
+			log.Printf("store: %s 복구 실패: %s", storeEditsPath(), werr)
+		}
+		return nil, err
+	}
+	return ch, nil
+}
+
+// renamedKeys: 수정한 가게의 Key가 바뀌었으면 Change.Renamed. 새 가게(key가 빈 값)는 nil
+func renamedKeys(key string, s *Store) map[string]string {
+	key, newKey := NFC(key), NFC(s.Key())
+	if key == "" || key == newKey {
+		return nil
+	}
+	return map[string]string{key: newKey}
+}
+
+// markdownPath: Markdown 본문 파일의 기본 위치. HTML 본문과 같은 디렉토리
+func (s *Store) markdownPath() string { return strings.TrimSuffix(s.viewPath(), ".html") + ".md" }
+
+// saveMarkdown: Markdown 가게는 stores.json 대신 .md 파일의 front matter에 저장.
+// 기본 위치의 파일이면 지역, 업종, 상호가 바뀔 때 새 위치로 옮김. editsMu를 잡고 호출
+func saveMarkdown(old, s *Store, edits []*storeEdit) (ch *Change, err error) {
+	prev, err := os.ReadFile(old.source)
+	if err != nil {
+		return nil, err
+	}
+	_, body, err := splitFrontMatter(prev)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", old.source, err)
+	}
+	b, err := markdownSource(s, body)
+	if err != nil {
+		return nil, err
+	}
+	// 예전에 stores.json에 저장한 수정 내용이 있으면 front matter를 덮어쓰므로 지움
+	kept := []*storeEdit{}
+	for _, e := range edits {
+		if e.Store == nil || e.Store.Key() != old.Key() {
+			kept = append(kept, e)
+		}
+	}
+	if len(kept) != len(edits) {
+		if err := writeStoreEdits(kept); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err != nil {
+				if werr := writeStoreEdits(edits); werr != nil {
+					log.Printf("store: %s 복구 실패: %s", storeEditsPath(), werr)
+				}
+			}
+		}()
+	}
+
+	dest := old.source
+	if NFC(old.source) == NFC(old.markdownPath()) {
+		dest = resolveNFC(s.markdownPath())
+	}
+	if err = renameStoreFiles(old, s); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			renameStoreFiles(s, old)
+		}
+	}()
+	if dest != old.source {
+		if _, err = os.Stat(dest); err == nil {
+			return nil, fmt.Errorf("%s: 이미 있는 파일입니다", dest)
+		}
+		if err = os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+			return nil, err
+		}
+	}
+	if err = writeFile(dest, b); err != nil {
+		return nil, err
+	}
+	if dest != old.source {
+		if err = os.Remove(old.source); err != nil {
+			os.Remove(dest)
+			return nil, err
+		}
+	}
+	if ch, err = reload(renamedKeys(old.Key(), s)); err != nil {
+		if dest != old.source {
+			os.Remove(dest)
+		}
+		if werr := writeFile(old.source, prev); werr != nil {
+			log.Printf("store: %s 복구 실패: %s", old.source, werr)
+		}
+		return nil, err
+	}
+	return ch, nil
+}
+
+// SetClosed: 가게 폐업 처리(closed=true) 또는 영업 재개
+func SetClosed(key string, closed bool, reason string) (*Change, error) {
+	old, has := FindStore(key)
+	if !has {
+		return nil, ErrStoreNotFound
+	}
+	s := old.clone()
+	s.Active = &Active{IsPermanentClosed: closed}
+	if closed {
+		s.Active.Reason = reason
+	}
+	return Save(key, s)
+}
+
+// FindStore: 모든 사이트의 가게 중 key에 해당하는 가게
+func FindStore(key string) (*Store, bool) {
+	key = NFC(key)
+	for _, s := range ListAllStores() {
+		if s.Key() == key {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// clone: 카탈로그의 가게는 여러 요청이 같이 읽으므로 수정할 때는 복사본 사용
+func (s *Store) clone() *Store {
+	x := *s
+	l, a, m := *s.Location, *s.Active, *s.Menu
+	x.Location, x.Active, x.Menu = &l, &a, &m
+	h := &Hour{}
+	if s.Hour.Part1 != nil {
+		p := *s.Hour.Part1
+		h.Part1 = &p
+	}
+	if s.Hour.Part2 != nil {
+		p := *s.Hour.Part2
+		h.Part2 = &p
+	}
+	x.Hour = h
+	x.Gallery, x.Keywords = nil, nil
+	return &x
+}
+
+// viewPath: 가게 본문 템플릿 파일
+func (s *Store) viewPath() string {
+	return fmt.Sprintf("views/store/%s/%s/%s/%s/%s.html",
+		s.Location.Do, s.Location.Si, s.Location.Dong, s.Type, s.Title)
+}
+
+// bodyPath: 디스크에 있는 본문 파일. Markdown 가게는 front matter를 포함한 .md 파일
+func (s *Store) bodyPath() string {
+	if s.IsMarkdown() {
+		return s.source
+	}
+	return resolveNFC(s.viewPath())
+}
+
+// BodySource: 가게 본문 파일 내용
+func (s *Store) BodySource() (string, error) {
+	b, err := os.ReadFile(s.bodyPath())
+	return string(b), err
+}
+
+func writeFile(path string, b []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// SaveBodySource: 가게 본문 파일 저장. HTML 템플릿은 서버가 변경을 감지해서 다시 읽고,
+// Markdown 파일은 카탈로그를 다시 만듦. 검사에 실패하면 이전 내용으로 되돌림
+func (s *Store) SaveBodySource(body string) error {
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	if !s.IsMarkdown() {
+		return writeFile(s.bodyPath(), []byte(body))
+	}
+	editsMu.Lock()
+	defer editsMu.Unlock()
+	prev, err := os.ReadFile(s.source)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(s.source, []byte(body)); err != nil {
+		return err
+	}
+	if _, err := Reload(); err != nil {
+		if werr := writeFile(s.source, prev); werr != nil {
+			log.Printf("store: %s 복구 실패: %s", s.source, werr)
+		}
+		return err
+	}
+	return nil
+}
+
+// ImageDir: 가게 이미지를 저장하는 디스크의 디렉토리. 이름이 NFD인 디렉토리가 있으면 그 디렉토리
+func (s *Store) ImageDir() string { return resolveNFC(s.imageDir()) }
@@ -0,0 +1,148 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 이미지 디렉토리에 함께 두는 갤러리 메타데이터 파일 이름
+const galleryMetaFileName = "gallery.json"
+
+var galleryExtensions = []string{".png", ".jpg", ".jpeg", ".webp", ".gif"}
+
+type Image struct {
+	// FileName: ex) 1.png
+	FileName string
+	// Path: 웹 경로. ex) /static/img/store/서울/강남구/역삼동/쩜오/에프원/1.png
+	Path string
+	// Caption: 이미지 설명. gallery.json에서 입력
+	Caption string
+	// Alt: 이미지 대체 텍스트. gallery.json에서 입력
+	Alt string
+}
+
+// galleryMeta: gallery.json의 항목. 파일에 적힌 순서가 갤러리 순서가 됨
+type galleryMeta struct {
+	File    string `json:"file"`
+	Caption string `json:"caption"`
+	Alt     string `json:"alt"`
+}
+
+func (s *Store) imageDir() string {
+	return fmt.Sprintf("static/img/store/%s/%s/%s/%s/%s",
+		s.Location.Do, s.Location.Si, s.Location.Dong, s.Type, s.Title)
+}
+
+// IsImageFile: 가게 이미지로 쓸 수 있는 확장자인지
+func IsImageFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, x := range galleryExtensions {
+		if ext == x {
+			return true
+		}
+	}
+	return false
+}
+
+func isGalleryImage(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	if strings.TrimSuffix(strings.ToLower(name), ext) == "thumbnail" {
+		return false
+	}
+	return IsImageFile(name)
+}
+
+// lessFileName: 숫자 파일명은 숫자 크기로 비교. ex) 2.png < 10.png
+func lessFileName(a, b string) bool {
+	na, errA := strconv.Atoi(strings.TrimSuffix(a, filepath.Ext(a)))
+	nb, errB := strconv.Atoi(strings.TrimSuffix(b, filepath.Ext(b)))
+	switch {
+	case errA == nil && errB == nil:
+		return na < nb
+	case errA == nil:
+		return true
+	case errB == nil:
+		return false
+	}
+	return a < b
+}
+
+func readGalleryMeta(dir string) ([]*galleryMeta, error) {
+	b, err := os.ReadFile(filepath.Join(dir, galleryMetaFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	list := []*galleryMeta{}
+	if err := json.Unmarshal(b, &list); err != nil {
+		return nil, fmt.Errorf("%s/%s: %w", dir, galleryMetaFileName, err)
+	}
+	return list, nil
+}
+
+// discoverGallery: 가게 이미지 디렉토리에서 thumbnail을 제외한 이미지를 찾아 갤러리 생성.
+// gallery.json에 적힌 이미지가 먼저 그 순서대로 오고, 나머지는 파일명 순서로 뒤에 붙음
+func discoverGallery(s *Store) ([]*Image, error) {
+	// 디렉토리 이름이 NFD면 웹 경로도 디스크의 이름을 써야 /static에서 찾음
+	dir, has := FindNFC(s.imageDir())
+	if !has {
+		dir = s.imageDir()
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{}
+	for _, e := range entries {
+		if e.IsDir() || !isGalleryImage(e.Name()) {
+			continue
+		}
+		files = append(files, e.Name())
+	}
+	sort.Slice(files, func(i, j int) bool { return lessFileName(files[i], files[j]) })
+
+	metas, err := readGalleryMeta(dir)
+	if err != nil {
+		return nil, err
+	}
+	list := []*Image{}
+	used := map[string]bool{}
+	for _, m := range metas {
+		if used[m.File] {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, m.File)); err != nil {
+			return nil, fmt.Errorf("%s/%s: %s 파일이 없습니다", dir, galleryMetaFileName, m.File)
+		}
+		used[m.File] = true
+		list = append(list, &Image{FileName: m.File, Caption: m.Caption, Alt: m.Alt})
+	}
+	for _, f := range files {
+		if used[f] {
+			continue
+		}
+		list = append(list, &Image{FileName: f})
+	}
+	for _, img := range list {
+		img.Path = "/" + filepath.ToSlash(dir) + "/" + img.FileName
+	}
+	return list, nil
+}
+
+func setGalleries() error {
+	for _, s := range stores {
+		gallery, err := discoverGallery(s)
+		if err != nil {
+			return err
+		}
+		s.Gallery = gallery
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"gopkg.in/yaml.v3"
+)
+
+// markdownDir: 가게 Markdown 파일을 찾는 디렉토리. HTML 본문과 같은 위치에 둠.
+// ex) views/store/서울/강남구/역삼동/쩜오/에이원.md
+const markdownDir = "views/store"
+
+// frontMatter: Markdown 파일 맨 위 --- 사이의 YAML. ex)
+//
+//	---
+//	location:
+//	  code: "1168010100"   # 법정동 코드. 생략하면 do, si, dong 이름으로 찾음
+//	  do: 서울
+//	  si: 강남구
+//	  dong: 역삼동
+//	  address: 735-32
+//	  roadAddress: 테헤란로 123   # 도로명주소. 모르면 생략
+//	  buildingName: 성담빌딩      # 없으면 생략
+//	  googleMapSrc: https://www.google.com/maps/embed?pb=...
+//	type: 쩜오
+//	title: 에이원
+//	description: 강남 에이원 쩜오는 ...
+//	author: colagom          # 작성자 Slug. 생략하면 사이트 기본 작성자
+//	closed: 리모델링        # 폐업이면 사유, 영업중이면 생략
+//	hour:
+//	  part1: {open: "18:00", closed: "05:00"}
+//	  part2: {open: "05:00", closed: "15:00"}   # 2부가 없으면 생략
+//	menu: {part1Whisky: 350000, part2Whisky: 160000, tc: 120000, rt: 50000}
+//	datePublished: 2023-09-05
+//	dateModified: 2023-10-15
+//	---
+type frontMatter struct {
+	Location struct {
+		Code         string  `yaml:"code,omitempty"`
+		Do           string  `yaml:"do"`
+		Si           string  `yaml:"si"`
+		Dong         string  `yaml:"dong"`
+		Address      string  `yaml:"address"`
+		RoadAddress  string  `yaml:"roadAddress,omitempty"`
+		BuildingName string  `yaml:"buildingName,omitempty"`
+		GoogleMapSrc string  `yaml:"googleMapSrc,omitempty"`
+		Latitude     float64 `yaml:"latitude,omitempty"`
+		Longitude    float64 `yaml:"longitude,omitempty"`
+	} `yaml:"location"`
+	Type        string `yaml:"type"`
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	Author      string `yaml:"author,omitempty"`
+	Closed      string `yaml:"closed,omitempty"`
+	Hour        struct {
+		Part1 *frontMatterTime `yaml:"part1,omitempty"`
+		Part2 *frontMatterTime `yaml:"part2,omitempty"`
+	} `yaml:"hour"`
+	Menu struct {
+		Part1Whisky int `yaml:"part1Whisky"`
+		Part2Whisky int `yaml:"part2Whisky"`
+		TC          int `yaml:"tc"`
+		RT          int `yaml:"rt"`
+	} `yaml:"menu"`
+	DatePublished string `yaml:"datePublished"`
+	DateModified  string `yaml:"dateModified,omitempty"`
+}
+
+type frontMatterTime struct {
+	Open   string `yaml:"open"`
+	Closed string `yaml:"closed"`
+}
+
+func (t *frontMatterTime) timeType() *TimeType {
+	if t == nil {
+		return &TimeType{}
+	}
+	return &TimeType{Has: true, Open: t.Open, Closed: t.Closed}
+}
+
+func newFrontMatterTime(t *TimeType) *frontMatterTime {
+	if t == nil || !t.Has {
+		return nil
+	}
+	return &frontMatterTime{Open: t.Open, Closed: t.Closed}
+}
+
+// newFrontMatter: 관리자 화면에서 수정한 가게를 Markdown 파일에 다시 쓸 때 사용. store()의 반대
+func newFrontMatter(s *Store) *frontMatter {
+	f := &frontMatter{
+		Type:          s.Type,
+		Title:         s.Title,
+		Description:   s.Description,
+		Author:        s.Author,
+		DatePublished: s.DatePublished.Format("2006-01-02"),
+		DateModified:  s.DateModified.Format("2006-01-02"),
+	}
+	l := s.Location
+	f.Location.Code, f.Location.Do, f.Location.Si, f.Location.Dong = l.Code, l.Do, l.Si, l.Dong
+	f.Location.Address, f.Location.RoadAddress, f.Location.BuildingName = l.Address, l.RoadAddress, l.BuildingName
+	f.Location.GoogleMapSrc, f.Location.Latitude, f.Location.Longitude = l.GoogleMapSrc, l.Latitude, l.Longitude
+	if s.Active != nil && s.Active.IsPermanentClosed {
+		f.Closed = s.Active.Reason
+	}
+	f.Hour.Part1, f.Hour.Part2 = newFrontMatterTime(s.Hour.Part1), newFrontMatterTime(s.Hour.Part2)
+	f.Menu.Part1Whisky, f.Menu.Part2Whisky, f.Menu.TC, f.Menu.RT = s.Menu.Part1Whisky, s.Menu.Part2Whisky, s.Menu.TC, s.Menu.RT
+	return f
+}
+
+// markdownSource: s의 front matter와 Markdown 본문으로 만든 .md 파일 내용
+func markdownSource(s *Store, body []byte) ([]byte, error) {
+	b := bytes.NewBufferString("---\n")
+	enc := yaml.NewEncoder(b)
+	enc.SetIndent(2)
+	if err := enc.Encode(newFrontMatter(s)); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	b.WriteString("---\n")
+	b.Write(body)
+	return b.Bytes(), nil
+}
+
+func parseStoreDate(field, v string) (time.Time, error) {
+	t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+	if err != nil {
+		return t, fmt.Errorf("%s: 날짜 형식은 YYYY-MM-DD: %q", field, v)
+	}
+	return t, nil
+}
+
+func (f *frontMatter) store() (*Store, error) {
+	s := &Store{
+		Location: &Location{
+			Code:         f.Location.Code,
+			Do:           NFC(f.Location.Do),
+			Si:           NFC(f.Location.Si),
+			Dong:         NFC(f.Location.Dong),
+			Address:      f.Location.Address,
+			RoadAddress:  f.Location.RoadAddress,
+			BuildingName: f.Location.BuildingName,
+			GoogleMapSrc: f.Location.GoogleMapSrc,
+			Latitude:     f.Location.Latitude,
+			Longitude:    f.Location.Longitude,
+		},
+		// macOS에서 만든 파일은 NFD일 수 있음. 코드의 가게와 같은 Key가 되도록 NFC
+		Type:        NFC(f.Type),
+		Title:       NFC(f.Title),
+		Description: f.Description,
+		Author:      f.Author,
+		Active:      &Active{IsPermanentClosed: f.Closed != "", Reason: f.Closed},
+		Hour:        &Hour{Part1: f.Hour.Part1.timeType(), Part2: f.Hour.Part2.timeType()},
+		Menu: &Menu{
+			Part1Whisky: f.Menu.Part1Whisky,
+			Part2Whisky: f.Menu.Part2Whisky,
+			TC:          f.Menu.TC,
+			RT:          f.Menu.RT,
+		},
+	}
+	resolveLocation(s.Location)
+	var err error
+	if s.DatePublished, err = parseStoreDate("datePublished", f.DatePublished); err != nil {
+		return nil, err
+	}
+	s.DateModified = s.DatePublished
+	if f.DateModified != "" {
+		if s.DateModified, err = parseStoreDate("dateModified", f.DateModified); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+var (
+	markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+	// sanitizer: Markdown 안에 직접 쓴 HTML도 script, on* 속성 등은 제거
+	sanitizer = bluemonday.UGCPolicy()
+)
+
+// splitFrontMatter: --- 로 시작하는 YAML과 나머지 Markdown 본문
+func splitFrontMatter(b []byte) (front, body []byte, err error) {
+	b = bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n"))
+	if !bytes.HasPrefix(b, []byte("---\n")) {
+		return nil, nil, errors.New("--- 로 시작하는 front matter가 없습니다")
+	}
+	rest := b[len("---\n"):]
+	i := bytes.Index(rest, []byte("\n---\n"))
+	if i == -1 {
+		if !bytes.HasSuffix(rest, []byte("\n---")) {
+			return nil, nil, errors.New("front matter가 --- 로 끝나지 않습니다")
+		}
+		return rest[:len(rest)-len("\n---")], nil, nil
+	}
+	return rest[:i], rest[i+len("\n---\n"):], nil
+}
+
+// parseMarkdownStore: front matter로 가게를 만들고 본문은 HTML로 변환
+func parseMarkdownStore(b []byte) (*Store, error) {
+	front, body, err := splitFrontMatter(b)
+	if err != nil {
+		return nil, err
+	}
+	f := &frontMatter{}
+	dec := yaml.NewDecoder(bytes.NewReader(front))
+	// 오타난 필드를 조용히 무시하지 않음
+	dec.KnownFields(true)
+	if err := dec.Decode(f); err != nil {
+		return nil, fmt.Errorf("front matter: %w", err)
+	}
+	s, err := f.store()
+	if err != nil {
+		return nil, err
+	}
+	html := &bytes.Buffer{}
+	if err := markdown.Convert(body, html); err != nil {
+		return nil, err
+	}
+	s.BodyHTML = template.HTML(sanitizer.SanitizeBytes(html.Bytes()))
+	return s, nil
+}
+
+// loadMarkdownStores: views/store 아래 .md 파일로 가게 추가.
+// 코드에 같은 가게가 있으면 Markdown 파일 내용으로 바꿈
+func loadMarkdownStores() error {
+	return filepath.WalkDir(markdownDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == markdownDir {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".md") {
+			return nil
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		s, err := parseMarkdownStore(b)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		s.source = path
+		for i, x := range stores {
+			if x.Location != nil && NFC(x.Key()) == s.Key() {
+				stores[i] = s
+				return nil
+			}
+		}
+		stores = append(stores, s)
+		return nil
+	})
+}
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"testing"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestSplitFrontMatter(t *testing.T) {
+	tests := []struct {
+		name        string
+		in          string
+		front, body string
+		err         bool
+	}{
+		{"front and body", "---\ntitle: a\n---\n# 본문\n", "title: a", "# 본문\n", false},
+		{"crlf", "---\r\ntitle: a\r\n---\r\n본문\r\n", "title: a", "본문\n", false},
+		{"no body", "---\ntitle: a\n---", "title: a", "", false},
+		{"empty body", "---\ntitle: a\n---\n", "title: a", "", false},
+		{"multi line front", "---\na: 1\nb: 2\n---\nx", "a: 1\nb: 2", "x", false},
+		// 본문 안의 --- (수평선)은 본문에 남김
+		{"rule in body", "---\na: 1\n---\n위\n---\n아래", "a: 1", "위\n---\n아래", false},
+		{"no opening", "title: a\n---\n", "", "", true},
+		{"leading blank line", "\n---\ntitle: a\n---\n", "", "", true},
+		{"no closing", "---\ntitle: a\n본문", "", "", true},
+		{"closing not on its own line", "---\ntitle: a ---\n", "", "", true},
+		{"empty", "", "", "", true},
+	}
+	for _, tt := range tests {
+		front, body, err := splitFrontMatter([]byte(tt.in))
+		if tt.err {
+			if err == nil {
+				t.Errorf("%s: want error, got front=%q body=%q", tt.name, front, body)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		if string(front) != tt.front || string(body) != tt.body {
+			t.Errorf("%s: got (%q, %q), want (%q, %q)", tt.name, front, body, tt.front, tt.body)
+		}
+	}
+}
+
+// macOS에서 만든 NFD 파일도 코드의 가게와 같은 Key
+func TestFrontMatterStoreNFC(t *testing.T) {
+	f := &frontMatter{Type: norm.NFD.String("쩜오"), Title: norm.NFD.String("에이원"), DatePublished: "2024-01-02"}
+	f.Location.Do, f.Location.Si, f.Location.Dong = norm.NFD.String("서울"), norm.NFD.String("강남구"), norm.NFD.String("역삼동")
+	s, err := f.store()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.Key(), "서울:강남구:역삼동:쩜오:에이원"; got != want || !norm.NFC.IsNormalString(got) {
+		t.Errorf("Key = %q, want NFC %q", got, want)
+	}
+	if s.Location.Code != "1168010100" {
+		t.Errorf("Code = %q, want 1168010100", s.Location.Code)
+	}
+}
+
+// 관리자 화면에서 저장한 front matter를 다시 읽으면 같은 가게
+func TestMarkdownSourceRoundTrip(t *testing.T) {
+	src := []byte("---\nlocation:\n  do: 서울\n  si: 강남구\n  dong: 역삼동\n  address: 735-32\n  latitude: 37.5\n  longitude: 127.03\n" +
+		"type: 쩜오\ntitle: 에이원\ndescription: 설명\nclosed: 리모델링\nhour:\n  part1: {open: \"18:00\", closed: \"05:00\"}\n" +
+		"menu: {part1Whisky: 350000, tc: 120000}\ndatePublished: 2023-09-05\ndateModified: 2023-10-15\n---\n# 본문\n\n내용\n")
+	s, err := parseMarkdownStore(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, body, err := splitFrontMatter(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := markdownSource(s, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := parseMarkdownStore(b)
+	if err != nil {
+		t.Fatalf("%s\n%s", err, b)
+	}
+	if got.Key() != s.Key() || got.Location.Latitude != 37.5 || got.Location.Longitude != 127.03 || got.Location.Code != s.Location.Code {
+		t.Errorf("location = %+v, want %+v", got.Location, s.Location)
+	}
+	if !got.Active.IsPermanentClosed || got.Active.Reason != "리모델링" {
+		t.Errorf("active = %+v", got.Active)
+	}
+	if *got.Hour.Part1 != *s.Hour.Part1 || got.Hour.Part2.Has {
+		t.Errorf("hour = %+v, %+v", got.Hour.Part1, got.Hour.Part2)
+	}
+	if *got.Menu != *s.Menu {
+		t.Errorf("menu = %+v, want %+v", got.Menu, s.Menu)
+	}
+	if !got.DatePublished.Equal(s.DatePublished) || !got.DateModified.Equal(s.DateModified) {
+		t.Errorf("dates = %s, %s", got.DatePublished, got.DateModified)
+	}
+	if got.BodyHTML != s.BodyHTML {
+		t.Errorf("body = %q, want %q", got.BodyHTML, s.BodyHTML)
+	}
+}
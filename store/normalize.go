@@ -0,0 +1,92 @@
+package store
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NFC: 한글 이름을 비교하기 전에 정규화.
+// macOS에서 만들거나 동기화한 파일 이름, 복사한 글자는 NFD(자모 분해)일 수 있어 화면에는 같아 보여도 문자열이 다름
+func NFC(s string) string { return norm.NFC.String(s) }
+
+// normalizeStores: 가게와 삭제한 가게의 지역, 업종, 상호를 NFC로. 카탈로그 키와 파일 경로가 같은 형태가 되도록 검사 전에 호출
+func normalizeStores() {
+	for _, s := range stores {
+		if s.Location != nil {
+			s.Location.Do, s.Location.Si, s.Location.Dong = NFC(s.Location.Do), NFC(s.Location.Si), NFC(s.Location.Dong)
+		}
+		s.Type, s.Title = NFC(s.Type), NFC(s.Title)
+	}
+	for _, r := range removals {
+		r.Location.Do, r.Location.Si, r.Location.Dong = NFC(r.Location.Do), NFC(r.Location.Si), NFC(r.Location.Dong)
+		r.Type, r.Title = NFC(r.Type), NFC(r.Title)
+	}
+}
+
+// FindNFC: p가 있으면 p. 없으면 경로를 한 단계씩 NFC로 비교해서 찾은 디스크의 실제 경로
+func FindNFC(p string) (string, bool) {
+	real := resolveNFC(p)
+	if _, err := os.Stat(real); err != nil {
+		return "", false
+	}
+	return real, true
+}
+
+// resolveNFC: FindNFC처럼 디스크의 실제 이름으로 바꾸되, 없는 부분부터는 p의 이름 그대로.
+// 새로 만들 파일도 이미 있는 NFD 디렉토리 아래에 만들어야 같은 이름의 디렉토리가 둘이 되지 않음
+func resolveNFC(p string) string {
+	if _, err := os.Stat(p); err == nil {
+		return p
+	}
+	real := "."
+	if filepath.IsAbs(p) {
+		real = string(filepath.Separator)
+	}
+	parts := strings.Split(filepath.Clean(p), string(filepath.Separator))
+	for i, part := range parts {
+		if part == "" || part == "." || part == ".." {
+			real = filepath.Join(real, part)
+			continue
+		}
+		entries, err := os.ReadDir(real)
+		if err != nil {
+			return filepath.Join(append([]string{real}, parts[i:]...)...)
+		}
+		found := false
+		for _, e := range entries {
+			if NFC(e.Name()) == NFC(part) {
+				real, found = filepath.Join(real, e.Name()), true
+				break
+			}
+		}
+		if !found {
+			return filepath.Join(append([]string{real}, parts[i:]...)...)
+		}
+	}
+	return real
+}
+
+// nfcCheckDirs: 가게 템플릿, 이미지 디렉토리. 이름이 NFC가 아니면 가게 경로로 찾지 못함
+var nfcCheckDirs = []string{"views/store", "static/img/store"}
+
+// nonNFCNames: root 아래 이름이 NFC가 아닌 파일, 디렉토리. root가 없으면 빈 목록
+func nonNFCNames(root string) ([]string, error) {
+	list := []string{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !norm.NFC.IsNormalString(d.Name()) {
+			list = append(list, path)
+		}
+		return nil
+	})
+	return list, err
+}
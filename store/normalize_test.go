@@ -0,0 +1,92 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// chdirTemp: 가게 파일 경로는 작업 디렉토리 기준이므로 임시 디렉토리로 이동
+func chdirTemp(t *testing.T) string {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	return dir
+}
+
+func nfdStore(t *testing.T, title string) *Store {
+	s := &Store{Location: &Location{Do: "서울", Si: "강남구", Dong: "역삼동"}, Type: "쩜오", Title: title}
+	// macOS에서 만든 것처럼 디스크에는 NFD 이름으로 저장
+	for _, dir := range []string{filepath.Dir(norm.NFD.String(s.viewPath())), norm.NFD.String(s.imageDir())} {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(norm.NFD.String(s.viewPath()), []byte("body"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestResolveNFC(t *testing.T) {
+	chdirTemp(t)
+	s := nfdStore(t, "에이원")
+	if got, has := FindNFC(s.viewPath()); !has || got != norm.NFD.String(s.viewPath()) {
+		t.Errorf("FindNFC = %q, %v", got, has)
+	}
+	body, err := s.BodySource()
+	if err != nil || body != "body" {
+		t.Errorf("BodySource = %q, %v", body, err)
+	}
+	// 없는 파일은 있는 디렉토리까지만 디스크의 이름
+	got := resolveNFC(filepath.Join(s.imageDir(), "새 이미지.png"))
+	if want := filepath.Join(norm.NFD.String(s.imageDir()), "새 이미지.png"); got != want {
+		t.Errorf("resolveNFC = %q, want %q", got, want)
+	}
+	if _, has := FindNFC("views/store/없는/경로.html"); has {
+		t.Error("FindNFC found a missing path")
+	}
+}
+
+// 본문 저장과 이름 바꾸기는 NFC 이름의 파일, 디렉토리를 새로 만들지 않아야 함
+func TestStoreFilesNFD(t *testing.T) {
+	chdirTemp(t)
+	old := nfdStore(t, "에이원")
+	if err := old.SaveBodySource("new body"); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(norm.NFD.String(old.viewPath()))
+	if err != nil || string(b) != "new body" {
+		t.Errorf("NFD body = %q, %v", b, err)
+	}
+
+	s := &Store{Location: old.Location, Type: old.Type, Title: "에프원"}
+	if err := renameStoreFiles(old, s); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{old.viewPath(), old.imageDir()} {
+		if _, has := FindNFC(p); has {
+			t.Errorf("%s left behind", p)
+		}
+	}
+	for _, p := range []string{s.viewPath(), s.imageDir()} {
+		if _, has := FindNFC(p); !has {
+			t.Errorf("%s not moved", p)
+		}
+	}
+	entries, err := os.ReadDir(filepath.Dir(resolveNFC(filepath.Dir(s.viewPath()))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("duplicate directories: %v", entries)
+	}
+}
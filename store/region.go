@@ -0,0 +1,91 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jeonghoikun/colagom.com/region"
+	"github.com/jeonghoikun/colagom.com/site"
+)
+
+// DongRegion: 가게 동. 알 수 없는 Code면 nil
+func (l *Location) DongRegion() *region.Region {
+	r, has := region.Get(l.Code)
+	if !has {
+		return nil
+	}
+	return r
+}
+
+// SiRegion: 가게 시군구. ex) 강남구
+func (l *Location) SiRegion() *region.Region { return l.DongRegion().Ancestor(region.Si) }
+
+// DoRegion: 가게 시도. ex) 서울
+func (l *Location) DoRegion() *region.Region { return l.DongRegion().Ancestor(region.Do) }
+
+// SiShort: 제목에 쓰는 시군구 이름. ex) 강남구 => 강남
+func (l *Location) SiShort() string { return region.ShortName(l.Do, l.Si) }
+
+// resolveLocation: Code가 있으면 Code로 Do, Si, Dong 이름을 채움.
+// Code가 없으면(Markdown, 관리자 화면) 이름으로 Code를 찾음. 못 찾으면 그대로 두고 validateLocation에서 에러
+func resolveLocation(l *Location) {
+	if l == nil {
+		return
+	}
+	if r, has := region.Get(l.Code); has && r.Level == region.Dong {
+		l.Do, l.Si, l.Dong = r.Ancestor(region.Do).Name, r.Ancestor(region.Si).Name, r.Name
+		return
+	}
+	if l.Code != "" {
+		return
+	}
+	if r, has := region.Find(NFC(l.Do), NFC(l.Si), NFC(l.Dong)); has {
+		l.Code = r.Code
+		l.Do, l.Si, l.Dong = r.Ancestor(region.Do).Name, r.Ancestor(region.Si).Name, r.Name
+	}
+}
+
+// resolveLocations: 가게와 삭제한 가게의 지역을 gazetteer(region 패키지) 이름으로 맞춤
+func resolveLocations() {
+	for _, s := range stores {
+		resolveLocation(s.Location)
+	}
+	for _, r := range removals {
+		resolveLocation(r.Location)
+	}
+}
+
+func validateLocation(l *Location) error {
+	if l.Code == "" {
+		return fmt.Errorf("Location: 알 수 없는 지역입니다: %s %s %s", l.Do, l.Si, l.Dong)
+	}
+	r, has := region.Get(l.Code)
+	if !has {
+		return fmt.Errorf("Location.Code: 알 수 없는 법정동 코드입니다: %q", l.Code)
+	}
+	if r.Level != region.Dong {
+		return fmt.Errorf("Location.Code: 동 코드가 아닙니다: %q (%s)", l.Code, r.FullName())
+	}
+	return nil
+}
+
+// validateSiteRegions: 사이트 설정의 Regions가 gazetteer에 없으면 에러
+func validateSiteRegions() error {
+	problems := []string{}
+	for _, cfg := range site.Sites {
+		for _, r := range cfg.Regions {
+			names := []string{r.Do}
+			if r.Si != "" {
+				names = append(names, r.Si)
+			}
+			if _, has := region.Find(names...); !has {
+				problems = append(problems, fmt.Sprintf("%s: 알 수 없는 지역입니다: %s", cfg.Domain, strings.Join(names, " ")))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
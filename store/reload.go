@@ -0,0 +1,106 @@
+package store
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// mu: stores, fingerprint 보호. Reload 중에는 이전 카탈로그를 계속 읽을 수 있도록
+// 새 slice를 만든 뒤 교체함
+var mu sync.RWMutex
+
+// LoadState: 카탈로그 로드 상태. /readyz에 사용
+type LoadState struct {
+	// LoadedAt: 마지막으로 로드에 성공한 시간. 한번도 성공하지 못했으면 zero
+	LoadedAt time.Time
+	Stores   int
+	// LastError: 마지막 Reload 실패. 실패해도 이전 카탈로그로 계속 서비스
+	LastError error
+	// Reloads, ReloadFailures: 서버 시작 후 Reload 성공, 실패 횟수
+	Reloads        int
+	ReloadFailures int
+}
+
+var loadState LoadState
+
+func CurrentLoadState() LoadState {
+	mu.RLock()
+	defer mu.RUnlock()
+	return loadState
+}
+
+type Change struct {
+	// Structural: 가게 추가, 삭제 등으로 카탈로그 구성(Fingerprint)이 바뀜
+	Structural bool
+	// Stores: 추가되었거나 내용이 바뀐 가게
+	Stores []*Store
+	// Renamed: Save로 지역, 업종, 상호가 바뀐 가게. 이전 Key => 새 Key
+	Renamed map[string]string
+}
+
+var (
+	subscribersMu sync.Mutex
+	subscribers   []func(*Change)
+)
+
+// Subscribe: Reload로 카탈로그가 바뀔 때마다 fn 호출
+func Subscribe(fn func(*Change)) {
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+	subscribers = append(subscribers, fn)
+}
+
+func notify(ch *Change) {
+	subscribersMu.Lock()
+	list := append([]func(*Change){}, subscribers...)
+	subscribersMu.Unlock()
+	for _, fn := range list {
+		fn(ch)
+	}
+}
+
+func diff(old []*Store, oldFingerprint string) *Change {
+	ch := &Change{Structural: oldFingerprint != fingerprint}
+	prev := map[string]*Store{}
+	for _, s := range old {
+		prev[s.Key()] = s
+	}
+	for _, s := range stores {
+		if o, has := prev[s.Key()]; !has || !reflect.DeepEqual(o, s) {
+			ch.Stores = append(ch.Stores, s)
+		}
+	}
+	return ch
+}
+
+// Reload: 카탈로그를 다시 만들고 바뀐 내용을 구독자에게 알림.
+// 실패하면 이전 카탈로그를 그대로 유지
+func Reload() (*Change, error) { return reload(nil) }
+
+// reload: renamed는 Change.Renamed로 구독자에게 전달
+func reload(renamed map[string]string) (*Change, error) {
+	mu.Lock()
+	old, oldFingerprint := stores, fingerprint
+	stores = []*Store{}
+	if err := load(); err != nil {
+		stores, fingerprint = old, oldFingerprint
+		loadState.LastError = err
+		loadState.ReloadFailures++
+		mu.Unlock()
+		return nil, err
+	}
+	loadState = LoadState{
+		LoadedAt:       time.Now(),
+		Stores:         len(stores),
+		Reloads:        loadState.Reloads + 1,
+		ReloadFailures: loadState.ReloadFailures,
+	}
+	ch := diff(old, oldFingerprint)
+	ch.Renamed = renamed
+	mu.Unlock()
+	if ch.Structural || len(ch.Stores) > 0 || len(ch.Renamed) > 0 {
+		notify(ch)
+	}
+	return ch, nil
+}
@@ -0,0 +1,101 @@
+package store
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jeonghoikun/colagom.com/station"
+)
+
+// nearbyStationCount: 가게 페이지에 보여줄 가까운 역 수
+const nearbyStationCount = 3
+
+// mapCoordinates: 구글 지도 embed src의 경도(!2d), 위도(!3d)
+var mapCoordinates = regexp.MustCompile(`!2d(-?[\d.]+)!3d(-?[\d.]+)`)
+
+// setCoordinates: 좌표를 입력하지 않았으면 GoogleMapSrc에서 찾음
+func setCoordinates(l *Location) {
+	if l.Latitude != 0 && l.Longitude != 0 {
+		return
+	}
+	m := mapCoordinates.FindStringSubmatch(l.GoogleMapSrc)
+	if m == nil {
+		return
+	}
+	lng, err1 := strconv.ParseFloat(m[1], 64)
+	lat, err2 := strconv.ParseFloat(m[2], 64)
+	if err1 != nil || err2 != nil {
+		return
+	}
+	l.Latitude, l.Longitude = lat, lng
+}
+
+// HasCoordinates: 좌표를 알 수 있는 가게
+func (l *Location) HasCoordinates() bool { return l.Latitude != 0 && l.Longitude != 0 }
+
+// Street: 도로명주소가 있으면 도로명주소, 없으면 동과 지번. ex) 테헤란로 411, 역삼동 822-5
+func (l *Location) Street() string {
+	if l.RoadAddress != "" {
+		return l.RoadAddress
+	}
+	if l.Address == "" {
+		return l.Dong
+	}
+	return l.Dong + " " + l.Address
+}
+
+func setStations() {
+	for _, s := range stores {
+		setCoordinates(s.Location)
+		s.Stations = nil
+		if s.Location.HasCoordinates() {
+			s.Stations = station.Nearest(s.Location.Latitude, s.Location.Longitude, nearbyStationCount)
+		}
+	}
+}
+
+// StationStore: 역 페이지의 가게와 역에서 가게까지 도보 거리
+type StationStore struct {
+	Store *Store
+	Walk  *station.Nearby
+}
+
+// StationGroup: 역 페이지의 업종별 가게 목록
+type StationGroup struct {
+	Type   string
+	Stores []*StationStore
+}
+
+// ListStoresNearStation: st에서 걸어서 meters 안에 있는 영업중인 가게. 업종별로 묶고 가까운 가게부터
+func (c *Catalog) ListStoresNearStation(st *station.Station, meters int) []*StationGroup {
+	groups := []*StationGroup{}
+	for _, t := range StoreTypes {
+		g := &StationGroup{Type: t}
+		for _, s := range c.ListAllStores() {
+			if s.Type != t || s.Active.IsPermanentClosed || !s.Location.HasCoordinates() {
+				continue
+			}
+			if w := st.Walk(s.Location.Latitude, s.Location.Longitude); w.Meters <= meters {
+				g.Stores = append(g.Stores, &StationStore{Store: s, Walk: w})
+			}
+		}
+		if len(g.Stores) == 0 {
+			continue
+		}
+		sort.SliceStable(g.Stores, func(i, j int) bool { return g.Stores[i].Walk.Meters < g.Stores[j].Walk.Meters })
+		groups = append(groups, g)
+	}
+	return groups
+}
+
+// ListStations: 걸어서 meters 안에 영업중인 가게가 있는 역. 역 페이지와 sitemap에 사용
+func (c *Catalog) ListStations(meters int) []*station.Station {
+	list := []*station.Station{}
+	for _, st := range station.List() {
+		if len(c.ListStoresNearStation(st, meters)) > 0 {
+			list = append(list, st)
+		}
+	}
+	return list
+}
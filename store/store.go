@@ -1,13 +1,17 @@
 package store
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
+	"html/template"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"time"
 
-	"github.com/jeonghoikun/colagom.com/site"
+	"github.com/jeonghoikun/colagom.com/station"
 )
 
 const (
@@ -22,8 +26,13 @@ const (
 
 var stores []*Store = []*Store{}
 
-func Get(do, si, dong, storeType, title string) (o *Store, has bool) {
-	for _, s := range stores {
+// fingerprint: 카탈로그 구성(가게 목록)이 바뀌면 달라지는 값. HTTP ETag에 사용
+var fingerprint string
+
+// Get: 경로 파라미터로 가게 찾기. 입력은 NFC로 정규화해서 비교
+func (c *Catalog) Get(do, si, dong, storeType, title string) (o *Store, has bool) {
+	do, si, dong, storeType, title = NFC(do), NFC(si), NFC(dong), NFC(storeType), NFC(title)
+	for _, s := range c.ListAllStores() {
 		if s.Location.Do == do && s.Location.Si == si && s.Location.Dong == dong &&
 			s.Type == storeType && s.Title == title {
 			return s, true
@@ -32,11 +41,42 @@ func Get(do, si, dong, storeType, title string) (o *Store, has bool) {
 	return nil, false
 }
 
-func ListAllStores() []*Store { return stores }
+// GetByKey: Key로 가게 찾기. ex) 서울:강남구:역삼동:쩜오:에이원
+func (c *Catalog) GetByKey(key string) (*Store, bool) {
+	parts := strings.Split(key, ":")
+	if len(parts) != 5 {
+		return nil, false
+	}
+	return c.Get(parts[0], parts[1], parts[2], parts[3], parts[4])
+}
+
+func ListAllStores() []*Store {
+	mu.RLock()
+	defer mu.RUnlock()
+	return stores
+}
+
+func Fingerprint() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return fingerprint
+}
+
+// LatestModified: 가게 목록 중 가장 최근 수정일
+func LatestModified(list []*Store) time.Time {
+	var t time.Time
+	for _, s := range list {
+		if s.DateModified.After(t) {
+			t = s.DateModified
+		}
+	}
+	return t
+}
 
-func ListStoresByDoSiAndStoreType(do, si, storeType string) []*Store {
+func (c *Catalog) ListStoresByDoSiAndStoreType(do, si, storeType string) []*Store {
+	do, si, storeType = NFC(do), NFC(si), NFC(storeType)
 	list := []*Store{}
-	for _, s := range stores {
+	for _, s := range c.ListAllStores() {
 		if s.Location.Do == do && s.Location.Si == si && s.Type == storeType {
 			list = append(list, s)
 		}
@@ -44,36 +84,51 @@ func ListStoresByDoSiAndStoreType(do, si, storeType string) []*Store {
 	return list
 }
 
+// Category: 한 지역(Do, Si)의 한 업종. 업종이 같아도 구가 다르면 다른 카테고리
 type Category struct {
-	Name   string
+	Do   string
+	Si   string
+	Name string
+	// Label: 메뉴에 보여줄 이름. 카탈로그에 구가 여러 개면 구 이름을 붙임. ex) 하이퍼블릭, 강남 하이퍼블릭
+	Label  string
 	Stores []*Store
 }
 
-func ListAllCategories() []*Category {
+// Path: 카테고리 페이지 경로. ex) /category/서울/강남구/쩜오
+func (c *Category) Path() string {
+	return fmt.Sprintf("/category/%s/%s/%s", c.Do, c.Si, c.Name)
+}
+
+// ListAllCategories: (Do, Si, Type)별 카테고리. 업종 이름순, 같은 업종은 지역순
+func (c *Catalog) ListAllCategories() []*Category {
 	list := []*Category{}
-	for _, s := range ListAllStores() {
-		ok := false
-		for _, c := range list {
-			if s.Type == c.Name {
-				ok = true
-				break
-			}
+	byKey := map[string]*Category{}
+	regions := map[string]bool{}
+	for _, s := range c.ListAllStores() {
+		l := s.Location
+		regions[l.Do+":"+l.Si] = true
+		key := l.Do + ":" + l.Si + ":" + s.Type
+		x, has := byKey[key]
+		if !has {
+			x = &Category{Do: l.Do, Si: l.Si, Name: s.Type, Label: s.Type}
+			byKey[key] = x
+			list = append(list, x)
 		}
-		if !ok {
-			list = append(list, &Category{
-				Name:   s.Type,
-				Stores: []*Store{s},
-			})
-			continue
+		x.Stores = append(x.Stores, s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Name != list[j].Name {
+			return list[i].Name < list[j].Name
 		}
-		for _, c := range list {
-			if s.Type == c.Name {
-				c.Stores = append(c.Stores, s)
-			}
+		if list[i].Do != list[j].Do {
+			return list[i].Do < list[j].Do
 		}
-	}
-	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+		return list[i].Si < list[j].Si
+	})
 	for _, x := range list {
+		if len(regions) > 1 {
+			x.Label = x.Stores[0].Location.SiShort() + " " + x.Name
+		}
 		sort.Slice(x.Stores, func(i, j int) bool {
 			return x.Stores[i].DatePublished.UnixNano() > x.Stores[j].DatePublished.UnixNano()
 		})
@@ -82,16 +137,25 @@ func ListAllCategories() []*Category {
 }
 
 type Location struct {
-	// Do: ex) 서울
+	// Code: 법정동 코드(region/gazetteer.go). ex) 1168010100 => 서울 강남구 역삼동
+	Code string
+	// Do: Code로 채움. URL과 Key에 사용. ex) 서울
 	Do string
-	// Si: ex) 강남구
+	// Si: Code로 채움. ex) 강남구
 	Si string
-	// Dong: ex) 역삼동
+	// Dong: Code로 채움. ex) 역삼동
 	Dong string
-	// Address: ex) 822-5
+	// Address: 지번. ex) 822-5
 	Address string
+	// RoadAddress: 도로명주소. 모르면 빈 값. ex) 테헤란로 411
+	RoadAddress string `json:",omitempty"`
+	// BuildingName: 건물 이름. 없으면 빈 값. ex) 성담빌딩
+	BuildingName string `json:",omitempty"`
 	// GoogleMapSrc: iframe google map의 src속성 값
 	GoogleMapSrc string
+	// Latitude, Longitude: 가게 좌표. 0이면 서버 시작시 GoogleMapSrc에서 채움
+	Latitude  float64 `json:",omitempty"`
+	Longitude float64 `json:",omitempty"`
 }
 
 type Keywords []string
@@ -99,7 +163,7 @@ type Keywords []string
 func (k *Keywords) String() string { return strings.Join(*k, ",") }
 
 type Active struct {
-	// IsPermanentClosed: 영업중=true 폐업=false
+	// IsPermanentClosed: 폐업=true 영업중=false
 	IsPermanentClosed bool
 	// Reason: 폐업상태일 경우에만 입력
 	Reason string
@@ -140,24 +204,69 @@ type Store struct {
 	Title string
 	// Description: 가게 설명 하드코딩
 	Description string
+	// Author: 작성자 Slug(author.go). 빈 값이면 사이트 기본 작성자
+	Author string `json:",omitempty"`
 	// Keywords: 하드코딩 X. 서버 시작시 지역명, 가게이름, 업종 등으로 자동 초기화 됨
-	Keywords Keywords
+	Keywords Keywords `json:"-"`
 	// Active: 영업, 폐업 유무와 폐업사유 하드코딩
 	Active *Active
 	// Hour: 영업시간 하드코딩
 	Hour *Hour
 	// Price: 가격 하드코딩
 	Menu *Menu
-	// PhoneNumber: 하드코딩 X.
-	PhoneNumber string
+	// Gallery: 하드코딩 X. 서버 시작시 static/img/store 디렉토리에서 자동 초기화 됨
+	Gallery []*Image `json:"-"`
+	// Stations: 하드코딩 X. 서버 시작시 좌표로 가까운 지하철역을 찾아 자동 초기화 됨
+	Stations []*station.Nearby `json:"-"`
+	// BodyHTML: 하드코딩 X. Markdown 파일로 만든 가게의 본문. HTML 템플릿 본문을 쓰는 가게는 빈 값
+	BodyHTML template.HTML `json:"-"`
+	// source: Markdown 파일 경로. 코드로 만든 가게는 빈 값
+	source string
 	// 생성일
 	DatePublished time.Time
 	// 수정일
 	DateModified time.Time
 }
 
+// Key: ex) 서울:강남구:역삼동:쩜오:에프원
+func (s *Store) Key() string {
+	return strings.Join([]string{s.Location.Do, s.Location.Si, s.Location.Dong, s.Type, s.Title}, ":")
+}
+
+// Path: 가게 페이지 경로. ex) /store/서울/강남구/역삼동/쩜오/에프원
+func (s *Store) Path() string {
+	return fmt.Sprintf("/store/%s/%s/%s/%s/%s", s.Location.Do, s.Location.Si, s.Location.Dong, s.Type, s.Title)
+}
+
+// IsMarkdown: Markdown 파일로 만든 가게. 본문은 views/store의 HTML 템플릿 대신 BodyHTML
+func (s *Store) IsMarkdown() bool { return s.source != "" }
+
 func (s *Store) IsModified() bool { return s.DatePublished.UnixNano() != s.DateModified.UnixNano() }
 
+// PriceFor: people명이 part부(1, 2)에 입실할 때 금액 합계(주대+TC×인원+RT).
+// 그 시간에 영업하지 않거나 주대가 없으면 0(문의)
+func (s *Store) PriceFor(part, people int) int {
+	t, whisky := s.Hour.Part1, s.Menu.Part1Whisky
+	if part == 2 {
+		t, whisky = s.Hour.Part2, s.Menu.Part2Whisky
+	}
+	if t == nil || !t.Has || whisky == 0 {
+		return 0
+	}
+	return whisky + s.Menu.TC*people + s.Menu.RT
+}
+
+// StartingPrice: 1인 입실시 가장 저렴한 금액(주대+TC+RT). 주대가 없으면 0(문의)
+func (s *Store) StartingPrice() int {
+	min := 0
+	for _, part := range []int{1, 2} {
+		if total := s.PriceFor(part, 1); total != 0 && (min == 0 || total < min) {
+			min = total
+		}
+	}
+	return min
+}
+
 func storeDate(year, month, day int) time.Time {
 	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.Local)
 }
@@ -165,9 +274,7 @@ func storeDate(year, month, day int) time.Time {
 func initKaraoke() {
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "논현동",
+			Code:         "1168010800",
 			Address:      "151-30",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3164.8479106529085!2d127.03145169999998!3d37.5115051!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca3f05b7c4407%3A0xbb44e0b5425b8a89!2z7ISc7Jq47Yq567OE7IucIOqwleuCqOq1rCDrhbztmITrj5kgMTUxLTMw!5e0!3m2!1sko!2skr!4v1660745693771!5m2!1sko!2skr",
 		},
@@ -188,10 +295,9 @@ func initKaraoke() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "삼성동",
+			Code:         "1168010500",
 			Address:      "142-35",
+			RoadAddress:  "테헤란로 411",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.1043050533926!2d127.05085469999999!3d37.505458!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca411d5a288d7%3A0xca6681460caa4840!2s411%20Teheran-ro%2C%20Gangnam-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1662046616801!5m2!1sen!2skr",
 		},
 		Type:        STORE_TYPE_KARAOKE,
@@ -214,10 +320,9 @@ func initKaraoke() {
 func initShirtRoom() {
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "삼성동",
+			Code:         "1168010500",
 			Address:      "142-35",
+			RoadAddress:  "테헤란로 411",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.1043050533926!2d127.05085469999999!3d37.505458!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca411d5a288d7%3A0xca6681460caa4840!2s411%20Teheran-ro%2C%20Gangnam-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1662046616801!5m2!1sen!2skr",
 		},
 		Type:        STORE_TYPE_SHIRTROOM,
@@ -237,9 +342,7 @@ func initShirtRoom() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "잠원동",
+			Code:         "1165010600",
 			Address:      "18-9",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3164.7060647283693!2d127.0171104!3d37.514850200000005!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca3dd364c8bc7%3A0x3ab4d058c71d79a8!2s18-9%20Jamwon-dong%2C%20Seocho-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1670862647642!5m2!1sen!2skr",
 		},
@@ -260,9 +363,7 @@ func initShirtRoom() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "삼성동",
+			Code:         "1168010500",
 			Address:      "143-27",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.0354982629583!2d127.0543849!3d37.5070809!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca413c457ed95%3A0x2c8f79900d733d24!2s143-27%20Samseong-dong%2C%20Gangnam-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1685329268008!5m2!1sen!2skr",
 		},
@@ -286,9 +387,7 @@ func initShirtRoom() {
 func initHighPublic() {
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "역삼동",
+			Code:         "1168010100",
 			Address:      "604-7",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.088372324827!2d127.0311099!3d37.5058338!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca3fb63865cd7%3A0x31427b556da83644!2s604-7%20Yeoksam-dong%2C%20Gangnam-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1662056274810!5m2!1sen!2skr",
 		},
@@ -309,9 +408,7 @@ func initHighPublic() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "역삼동",
+			Code:         "1168010100",
 			Address:      "604-7",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.088372324827!2d127.0311099!3d37.5058338!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca3fb63865cd7%3A0x31427b556da83644!2s604-7%20Yeoksam-dong%2C%20Gangnam-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1662056274810!5m2!1sen!2skr",
 		},
@@ -332,9 +429,7 @@ func initHighPublic() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "역삼동",
+			Code:         "1168010100",
 			Address:      "831-42",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.6005044881886!2d127.03146729999997!3d37.4937527!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca15057aba5c3%3A0x3c39e1c32ad3bd0f!2s831-42%20Yeoksam-dong%2C%20Gangnam-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1665731145337!5m2!1sen!2skr",
 		},
@@ -355,9 +450,7 @@ func initHighPublic() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "대치동",
+			Code:         "1168010600",
 			Address:      "890-38",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.150656732908!2d127.05328440000001!3d37.504364699999996!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca41055280155%3A0xc6516a6b77ef70c1!2z7ISc7Jq47Yq567OE7IucIOqwleuCqOq1rCDrjIDsuZjrj5kgODkwLTM4!5e0!3m2!1sko!2skr!4v1660489421580!5m2!1sko!2skr",
 		},
@@ -378,9 +471,7 @@ func initHighPublic() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "역삼동",
+			Code:         "1168010100",
 			Address:      "822-5",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.3849794120856!2d127.02926860000001!3d37.4988373!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca159d7d08f47%3A0x19ac7457d361928!2z7ISc7Jq47Yq567OE7IucIOqwleuCqOq1rCDthYztl6TrnoDroZwgMTEx!5e0!3m2!1sko!2skr!4v1661153125692!5m2!1sko!2skr",
 		},
@@ -401,9 +492,7 @@ func initHighPublic() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "역삼동",
+			Code:         "1168010100",
 			Address:      "823-30",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.4051104401256!2d127.03307020000001!3d37.4983624!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca1565c22d639%3A0x1fcb22298cd33520!2z7ISc7Jq47Yq567OE7IucIOqwleuCqOq1rCDsl63sgrzrj5kgODIzLTMw!5e0!3m2!1sko!2skr!4v1693829638202!5m2!1sko!2skr",
 		},
@@ -424,9 +513,7 @@ func initHighPublic() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "논현동",
+			Code:         "1168010800",
 			Address:      "151-30",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3164.8479106529085!2d127.03145169999998!3d37.5115051!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca3f05b7c4407%3A0xbb44e0b5425b8a89!2z7ISc7Jq47Yq567OE7IucIOqwleuCqOq1rCDrhbztmITrj5kgMTUxLTMw!5e0!3m2!1sko!2skr!4v1660745693771!5m2!1sko!2skr",
 		},
@@ -447,9 +534,7 @@ func initHighPublic() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "역삼동",
+			Code:         "1168010100",
 			Address:      "824-8",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.434083647925!2d127.0305156!3d37.4976789!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca15741b03c33%3A0xf28611c1cfc94af5!2z7ISc7Jq47Yq567OE7IucIOqwleuCqOq1rCDsl63sgrzrj5kgODI0LTg!5e0!3m2!1sko!2skr!4v1704324043037!5m2!1sko!2skr",
 		},
@@ -470,9 +555,7 @@ func initHighPublic() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "역삼동",
+			Code:         "1168010100",
 			Address:      "832-7",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.679446741483!2d127.02837221193238!3d37.49189017194145!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca1502738de7b%3A0x65a8ee648278baf2!2z7ISc7Jq47Yq567OE7IucIOqwleuCqOq1rCDsl63sgrzrj5kgODMyLTc!5e0!3m2!1sko!2skr!4v1704324092279!5m2!1sko!2skr",
 		},
@@ -493,9 +576,7 @@ func initHighPublic() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "잠원동",
+			Code:         "1165010600",
 			Address:      "18-9",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3164.7060647283693!2d127.0171104!3d37.514850200000005!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca3dd364c8bc7%3A0x3ab4d058c71d79a8!2s18-9%20Jamwon-dong%2C%20Seocho-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1670862647642!5m2!1sen!2skr",
 		},
@@ -519,9 +600,7 @@ func initHighPublic() {
 func initLeggingsRoom() {
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "삼성동",
+			Code:         "1168010500",
 			Address:      "144-10",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.0368804441946!2d127.0548939!3d37.5070483!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca413ea3ed99f%3A0xdd0a3d80af8a9047!2s144-10%20Samseong-dong%2C%20Gangnam-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1662646930422!5m2!1sen!2skr",
 		},
@@ -545,9 +624,7 @@ func initLeggingsRoom() {
 func initDot5() {
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "논현동",
+			Code:         "1168010800",
 			Address:      "204-4",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.069981650343!2d127.02487893188555!3d37.50626757076464!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca3fb554ff02b%3A0x8d9e573a46ec1b7a!2s204-4%20Nonhyeon-dong%2C%20Gangnam-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1679716196560!5m2!1sen!2skr",
 		},
@@ -568,9 +645,7 @@ func initDot5() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "역삼동",
+			Code:         "1168010100",
 			Address:      "831",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.5641798788934!2d127.0297203!3d37.4946097!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca1508715f00d%3A0xf4d079a0f225c1b1!2s831%20Yeoksam-dong%2C%20Gangnam-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1679397724056!5m2!1sen!2skr",
 		},
@@ -591,9 +666,7 @@ func initDot5() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "역삼동",
+			Code:         "1168010100",
 			Address:      "735-32",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.3760308056935!2d127.0341289!3d37.4990484!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca1560e5d6327%3A0x5c114aeb8260a643!2s735-32%20Yeoksam-dong%2C%20Gangnam-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1679397562888!5m2!1sen!2skr",
 		},
@@ -614,9 +687,7 @@ func initDot5() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "삼성동",
+			Code:         "1168010500",
 			Address:      "141-33",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.121539211326!2d127.04949690000001!3d37.5050515!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca40fc775ade5%3A0xdd9b10797e776ad1!2s141-33%20Samseong-dong%2C%20Gangnam-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1678667592079!5m2!1sen!2skr",
 		},
@@ -637,9 +708,7 @@ func initDot5() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "역삼동",
+			Code:         "1168010100",
 			Address:      "701-2",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.204884148887!2d127.0430503!3d37.5030856!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca406fc7ff209%3A0x341d4adf49840962!2s701-2%20Yeoksam-dong%2C%20Gangnam-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1678667437305!5m2!1sen!2skr",
 		},
@@ -660,9 +729,7 @@ func initDot5() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "신사동",
+			Code:         "1168010700",
 			Address:      "561-30",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3164.5256239750274!2d127.0258308!3d37.5191051!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca3ecf7b91b35%3A0x90e6eb4e73a5644e!2s561-30%20Sinsa-dong%2C%20Gangnam-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1678606137375!5m2!1sen!2skr",
 		},
@@ -683,10 +750,9 @@ func initDot5() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "논현동",
+			Code:         "1168010800",
 			Address:      "248-7",
+			RoadAddress:  "언주로 640",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3164.741047626004!2d127.03369181564705!3d37.51402523489071!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca3f415b07255%3A0x2162a0d614d3c110!2s640%20Eonju-ro%2C%20Gangnam-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1678605759071!5m2!1sen!2skr",
 		},
 		Type:        STORE_TYPE_DOT5,
@@ -706,9 +772,7 @@ func initDot5() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "역삼동",
+			Code:         "1168010100",
 			Address:      "731-11",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.401460674176!2d127.0436794!3d37.498448499999995!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca401a6b8183b%3A0xcbcd58a8b2cb7c50!2s731%20Yeoksam-dong%2C%20Gangnam-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1678605118720!5m2!1sen!2skr",
 		},
@@ -729,9 +793,7 @@ func initDot5() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "역삼동",
+			Code:         "1168010100",
 			Address:      "736-17",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.3715755621406!2d127.03453809999999!3d37.4991535!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca15607cff005%3A0x9a314c8436603f9e!2s736-17%20Yeoksam-dong%2C%20Gangnam-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1677802895674!5m2!1sen!2skr",
 		},
@@ -752,9 +814,7 @@ func initDot5() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "역삼동",
+			Code:         "1168010100",
 			Address:      "824-7",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.429128349951!2d127.03037690000001!3d37.4977958!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca1576a139921%3A0xda0428a0d46a18b2!2s824-7%20Yeoksam-dong%2C%20Gangnam-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1676634190100!5m2!1sen!2skr",
 		},
@@ -775,9 +835,7 @@ func initDot5() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "역삼동",
+			Code:         "1168010100",
 			Address:      "702-16",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.158957771797!2d127.0454229!3d37.504168899999996!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca405e2735e15%3A0xc330c6245a409809!2s702-16%20Yeoksam-dong%2C%20Gangnam-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1661933858945!5m2!1sen!2skr",
 		},
@@ -798,9 +856,7 @@ func initDot5() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "역삼동",
+			Code:         "1168010100",
 			Address:      "677-22",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.2307457193765!2d127.03704181193267!3d37.50247557193869!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca3f8acb4cd37%3A0xa46ef02bf086e82c!2z7ISc7Jq47Yq567OE7IucIOqwleuCqOq1rCDsl63sgrzrj5kgNjc3LTIy!5e0!3m2!1sko!2skr!4v1704324149895!5m2!1sko!2skr",
 		},
@@ -821,9 +877,7 @@ func initDot5() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "역삼동",
+			Code:         "1168010100",
 			Address:      "701-2",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.204884148887!2d127.0430503!3d37.5030856!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca406fc7ff209%3A0x341d4adf49840962!2s701-2%20Yeoksam-dong%2C%20Gangnam-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1678667437305!5m2!1sen!2skr",
 		},
@@ -844,10 +898,9 @@ func initDot5() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "논현동",
+			Code:         "1168010800",
 			Address:      "248-7",
+			RoadAddress:  "언주로 640",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3164.741047626004!2d127.03369181564705!3d37.51402523489071!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca3f415b07255%3A0x2162a0d614d3c110!2s640%20Eonju-ro%2C%20Gangnam-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1678605759071!5m2!1sen!2skr",
 		},
 		Type:        STORE_TYPE_DOT5,
@@ -867,10 +920,9 @@ func initDot5() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "논현동",
+			Code:         "1168010800",
 			Address:      "248-7",
+			RoadAddress:  "언주로 640",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3164.741047626004!2d127.03369181564705!3d37.51402523489071!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca3f415b07255%3A0x2162a0d614d3c110!2s640%20Eonju-ro%2C%20Gangnam-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1678605759071!5m2!1sen!2skr",
 		},
 		Type:        STORE_TYPE_DOT5,
@@ -890,9 +942,7 @@ func initDot5() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "역삼동",
+			Code:         "1168010100",
 			Address:      "831",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.5641798788934!2d127.0297203!3d37.4946097!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca1508715f00d%3A0xf4d079a0f225c1b1!2s831%20Yeoksam-dong%2C%20Gangnam-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1679397724056!5m2!1sen!2skr",
 		},
@@ -913,9 +963,7 @@ func initDot5() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "역삼동",
+			Code:         "1168010100",
 			Address:      "822-5",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.3849794120856!2d127.02926860000001!3d37.4988373!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca159d7d08f47%3A0x19ac7457d361928!2z7ISc7Jq47Yq567OE7IucIOqwleuCqOq1rCDthYztl6TrnoDroZwgMTEx!5e0!3m2!1sko!2skr!4v1661153125692!5m2!1sko!2skr",
 		},
@@ -939,10 +987,8 @@ func initDot5() {
 func initClub() {
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "도산대로",
-			Address:      "114",
+			Code:         "1168010700",
+			RoadAddress:  "도산대로 114",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3164.64159846425!2d127.02127!3d37.5163704!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca3e9a9f07727%3A0x4fcde2f83452e564!2s114%20Dosan-daero%2C%20Gangnam-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1681189780772!5m2!1sen!2skr",
 		},
 		Type:        STORE_TYPE_CLUB,
@@ -962,9 +1008,7 @@ func initClub() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "잠원동",
+			Code:         "1165010600",
 			Address:      "21-3",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3164.6962477822185!2d127.0192326!3d37.51508169999999!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca3e80fe94731%3A0xadedf946e74c560c!2s21-3%20Jamwon-dong%2C%20Seocho-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1681189358457!5m2!1sen!2skr",
 		},
@@ -988,9 +1032,7 @@ func initClub() {
 func initHobba() {
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "삼성동",
+			Code:         "1168010500",
 			Address:      "143-35",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.0622628938677!2d127.05028567647602!3d37.5064496275705!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca4118576f5e1%3A0xbc745a3337004851!2s143-35%20Samseong-dong%2C%20Gangnam-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1685329649613!5m2!1sen!2skr",
 		},
@@ -1011,9 +1053,7 @@ func initHobba() {
 	})
 	stores = append(stores, &Store{
 		Location: &Location{
-			Do:           "서울",
-			Si:           "강남구",
-			Dong:         "삼성동",
+			Code:         "1168010500",
 			Address:      "143-27",
 			GoogleMapSrc: "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3165.0354982629583!2d127.0543849!3d37.5070809!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x357ca413c457ed95%3A0x2c8f79900d733d24!2s143-27%20Samseong-dong%2C%20Gangnam-gu%2C%20Seoul!5e0!3m2!1sen!2skr!4v1685329268008!5m2!1sen!2skr",
 		},
@@ -1049,17 +1089,13 @@ func setStoreKeywords() {
 	}
 }
 
-func setPhoneNumbers() {
+func setFingerprint() {
+	h := sha1.New()
 	for _, s := range stores {
-		switch s.Type {
-		case STORE_TYPE_DOT5:
-			s.PhoneNumber = "010-2170-4981"
-		case STORE_TYPE_CLUB, STORE_TYPE_HOBBA:
-			s.PhoneNumber = "010-6590-7589"
-		default:
-			s.PhoneNumber = site.Config.PhoneNumber
-		}
+		h.Write([]byte(s.Key()))
+		h.Write([]byte{'\n'})
 	}
+	fingerprint = hex.EncodeToString(h.Sum(nil))[:12]
 }
 
 func sortStores() {
@@ -1071,9 +1107,10 @@ func sortStores() {
 // 서버 시작시 vieiws/store directories 자동 생성
 func createViewsDirectories() error {
 	for _, s := range stores {
-		dir := fmt.Sprintf("views/store/%s/%s/%s/%s",
-			s.Location.Do, s.Location.Si, s.Location.Dong, s.Type)
-		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		if _, has := FindNFC(filepath.Dir(s.viewPath())); has {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(s.viewPath()), os.ModePerm); err != nil {
 			return err
 		}
 	}
@@ -1083,12 +1120,13 @@ func createViewsDirectories() error {
 // 서버 시작시 views/store/../../{{store.Title}}.html 파일 자동 생성
 func createHTMLFiles() error {
 	for _, s := range stores {
-		filepath := fmt.Sprintf("views/store/%s/%s/%s/%s/%s.html",
-			s.Location.Do, s.Location.Si, s.Location.Dong, s.Type, s.Title)
-		if _, err := os.Stat(filepath); err == nil {
+		if s.IsMarkdown() {
+			continue
+		}
+		if _, has := FindNFC(s.viewPath()); has {
 			continue
 		}
-		if err := os.WriteFile(filepath, []byte("write me!"), os.ModePerm); err != nil {
+		if err := os.WriteFile(s.viewPath(), []byte("write me!"), os.ModePerm); err != nil {
 			return err
 		}
 	}
@@ -1098,16 +1136,18 @@ func createHTMLFiles() error {
 // 서버 시작시 store 이미지 디렉토리 자동 생성
 func createStaticImgDirectories() error {
 	for _, s := range stores {
-		dir := fmt.Sprintf("static/img/store/%s/%s/%s/%s/%s",
-			s.Location.Do, s.Location.Si, s.Location.Dong, s.Type, s.Title)
-		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		if _, has := FindNFC(s.imageDir()); has {
+			continue
+		}
+		if err := os.MkdirAll(s.imageDir(), os.ModePerm); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func Init() error {
+// load: 카탈로그 생성. mu.Lock 상태에서 호출
+func load() error {
 	initKaraoke()
 	initShirtRoom()
 	initHighPublic()
@@ -1115,11 +1155,34 @@ func Init() error {
 	initDot5()
 	initClub()
 	initHobba()
+	resolveLocations()
+
+	if err := loadMarkdownStores(); err != nil {
+		return err
+	}
+	if err := applyStoreEdits(); err != nil {
+		return err
+	}
+	normalizeStores()
+	resolveLocations()
+	if err := validateAuthors(); err != nil {
+		return err
+	}
+	if err := validateSiteRegions(); err != nil {
+		return err
+	}
+	if err := validateStores(); err != nil {
+		return err
+	}
 
 	sortStores()
+	setFingerprint()
 
 	setStoreKeywords()
-	setPhoneNumbers()
+	setStations()
+	if err := loadContactRules(); err != nil {
+		return err
+	}
 
 	if err := createViewsDirectories(); err != nil {
 		return err
@@ -1130,5 +1193,20 @@ func Init() error {
 	if err := createStaticImgDirectories(); err != nil {
 		return err
 	}
+	if err := setGalleries(); err != nil {
+		return err
+	}
+	logWarnings(validate())
+	return nil
+}
+
+func Init() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if err := load(); err != nil {
+		loadState.LastError = err
+		return err
+	}
+	loadState = LoadState{LoadedAt: time.Now(), Stores: len(stores)}
 	return nil
 }
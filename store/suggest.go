@@ -0,0 +1,167 @@
+package store
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Removal: 카탈로그에서 의도적으로 삭제한 가게. 해당 가게 페이지는 410 응답
+type Removal struct {
+	Location *Location
+	Type     string
+	Title    string
+	// Reason: 삭제 사유
+	Reason string
+	// DateRemoved: 삭제일
+	DateRemoved time.Time
+}
+
+// removals: 삭제한 가게는 stores에서 지우고 여기에 추가. ex)
+//
+//	removals = append(removals, &Removal{
+//		Location:    &Location{Code: "1168010100"},
+//		Type:        STORE_TYPE_DOT5,
+//		Title:       "가게이름",
+//		Reason:      "정보 삭제 요청",
+//		DateRemoved: storeDate(2024, 6, 1),
+//	})
+var removals = []*Removal{}
+
+func GetRemoval(do, si, dong, storeType, title string) (*Removal, bool) {
+	do, si, dong, storeType, title = NFC(do), NFC(si), NFC(dong), NFC(storeType), NFC(title)
+	for _, r := range removals {
+		if r.Location.Do == do && r.Location.Si == si && r.Location.Dong == dong &&
+			r.Type == storeType && r.Title == title {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// MovedStore: 지역(구, 동)을 바로잡아 주소가 바뀐 가게. 예전 주소로 들어오면 새 주소로 보냄.
+// 같은 시도에서 업종과 상호가 같은 가게가 하나뿐일 때만 찾음
+func (c *Catalog) MovedStore(do, storeType, title string) (*Store, bool) {
+	do, storeType, title = NFC(do), NFC(storeType), NFC(title)
+	var found *Store
+	for _, s := range c.ListAllStores() {
+		if s.Location.Do != do || s.Type != storeType || s.Title != title {
+			continue
+		}
+		if found != nil {
+			return nil, false
+		}
+		found = s
+	}
+	return found, found != nil
+}
+
+// jamo: 한글 음절을 초성, 중성, 종성으로 분해. ex) 볼 -> ㅂ ㅗ ㄹ
+// 한 글자 오타가 음절 전체가 아니라 자모 하나 차이로 계산되도록 함
+func jamo(s string) []rune {
+	list := []rune{}
+	for _, r := range strings.ToLower(NFC(s)) {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		if r < 0xAC00 || r > 0xD7A3 {
+			list = append(list, r)
+			continue
+		}
+		i := r - 0xAC00
+		list = append(list, 0x1100+i/588, 0x1161+(i%588)/28)
+		if jong := i % 28; jong > 0 {
+			list = append(list, 0x11A7+jong)
+		}
+	}
+	return list
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// Distance: 자모 단위 편집거리
+func Distance(a, b string) int { return levenshtein(jamo(a), jamo(b)) }
+
+// similar: 편집거리가 입력 길이의 절반 이하일 때만 비슷하다고 판단
+func similar(query string, d int) bool {
+	limit := len(jamo(query)) / 2
+	if limit < 2 {
+		limit = 2
+	}
+	return d <= limit
+}
+
+// SuggestStores: 잘못 입력한 가게 주소와 가까운 가게 n개.
+// 상호 편집거리가 가장 중요하고, 동이나 업종이 다르면 1씩 더함
+func (c *Catalog) SuggestStores(dong, storeType, title string, n int) []*Store {
+	type scored struct {
+		s     *Store
+		score int
+	}
+	list := []*scored{}
+	for _, s := range c.ListAllStores() {
+		d := Distance(title, s.Title)
+		if !similar(title, d) {
+			continue
+		}
+		score := d * 2
+		if s.Location.Dong != dong {
+			score++
+		}
+		if s.Type != storeType {
+			score++
+		}
+		list = append(list, &scored{s: s, score: score})
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].score < list[j].score })
+	result := []*Store{}
+	for i := 0; i < len(list) && i < n; i++ {
+		result = append(result, list[i].s)
+	}
+	return result
+}
+
+// SuggestCategories: 잘못 입력한 업종과 가까운 카테고리 n개
+func (c *Catalog) SuggestCategories(storeType string, n int) []*Category {
+	categories := c.ListAllCategories()
+	sort.SliceStable(categories, func(i, j int) bool {
+		return Distance(storeType, categories[i].Name) < Distance(storeType, categories[j].Name)
+	})
+	result := []*Category{}
+	for _, x := range categories {
+		if len(result) == n || !similar(storeType, Distance(storeType, x.Name)) {
+			break
+		}
+		result = append(result, x)
+	}
+	return result
+}
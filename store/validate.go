@@ -0,0 +1,155 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// StoreTypes: 사용할 수 있는 업종
+var StoreTypes = []string{
+	STORE_TYPE_HIGHPUBLIC, STORE_TYPE_SHIRTROOM, STORE_TYPE_KARAOKE, STORE_TYPE_LEGGINGS,
+	STORE_TYPE_DOT5, STORE_TYPE_HOBBA, STORE_TYPE_CLUB,
+}
+
+// pathName: 지역, 업종, 상호는 URL과 파일 경로에 그대로 쓰이므로 경로 문자 금지
+func pathName(field, v string) error {
+	if strings.TrimSpace(v) == "" {
+		return fmt.Errorf("%s: 비어 있습니다", field)
+	}
+	if v != strings.TrimSpace(v) || v == "." || v == ".." || strings.ContainsAny(v, `/\:?#%`) {
+		return fmt.Errorf("%s: 사용할 수 없는 이름입니다: %q", field, v)
+	}
+	return nil
+}
+
+func validateTimeType(field string, t *TimeType) error {
+	if t == nil {
+		return fmt.Errorf("%s: 없습니다", field)
+	}
+	if !t.Has {
+		return nil
+	}
+	for _, v := range []string{t.Open, t.Closed} {
+		if _, err := time.Parse("15:04", v); err != nil {
+			return fmt.Errorf("%s: 시간 형식은 HH:MM: %q", field, v)
+		}
+	}
+	return nil
+}
+
+// validateStore: 카탈로그에 넣을 수 없는 가게면 에러
+func validateStore(s *Store) error {
+	if s.Location == nil {
+		return errors.New("Location: 없습니다")
+	}
+	if err := validateLocation(s.Location); err != nil {
+		return err
+	}
+	for _, f := range []struct{ name, v string }{
+		{"Location.Do", s.Location.Do},
+		{"Location.Si", s.Location.Si},
+		{"Location.Dong", s.Location.Dong},
+		{"Type", s.Type},
+		{"Title", s.Title},
+	} {
+		if err := pathName(f.name, f.v); err != nil {
+			return err
+		}
+	}
+	known := false
+	for _, t := range StoreTypes {
+		known = known || t == s.Type
+	}
+	if !known {
+		return fmt.Errorf("Type: 알 수 없는 업종입니다: %q", s.Type)
+	}
+	if _, has := GetAuthor(s.Author); s.Author != "" && !has {
+		return fmt.Errorf("Author: 알 수 없는 작성자입니다: %q", s.Author)
+	}
+	if s.Active == nil {
+		return errors.New("Active: 없습니다")
+	}
+	if s.Active.IsPermanentClosed && strings.TrimSpace(s.Active.Reason) == "" {
+		return errors.New("Active.Reason: 폐업 사유가 없습니다")
+	}
+	if s.Hour == nil {
+		return errors.New("Hour: 없습니다")
+	}
+	if err := validateTimeType("Hour.Part1", s.Hour.Part1); err != nil {
+		return err
+	}
+	if err := validateTimeType("Hour.Part2", s.Hour.Part2); err != nil {
+		return err
+	}
+	if s.Menu == nil {
+		return errors.New("Menu: 없습니다")
+	}
+	if s.Menu.Part1Whisky < 0 || s.Menu.Part2Whisky < 0 || s.Menu.TC < 0 || s.Menu.RT < 0 {
+		return errors.New("Menu: 가격은 0 이상이어야 합니다")
+	}
+	if s.DatePublished.IsZero() || s.DateModified.Before(s.DatePublished) {
+		return errors.New("DateModified: 생성일보다 이전입니다")
+	}
+	return nil
+}
+
+// validateStores: 가게 하나라도 잘못되었거나 키가 겹치면 에러. 카탈로그 로드를 막음
+func validateStores() error {
+	seen := map[string]bool{}
+	problems := []string{}
+	for _, s := range stores {
+		if err := validateStore(s); err != nil {
+			name := s.Title
+			if s.Location != nil {
+				name = s.Key()
+			}
+			problems = append(problems, fmt.Sprintf("%s: %s", name, err))
+			continue
+		}
+		if seen[s.Key()] {
+			problems = append(problems, fmt.Sprintf("%s: 같은 가게가 이미 있습니다", s.Key()))
+		}
+		seen[s.Key()] = true
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// validate: 카탈로그 로드시 가게 데이터를 검사하고 경고 목록을 반환.
+// 경고는 서버 시작을 막지 않음
+func validate() []string {
+	warnings := []string{}
+	for _, s := range stores {
+		noAlt := []string{}
+		for _, img := range s.Gallery {
+			if img.Alt == "" {
+				noAlt = append(noAlt, img.FileName)
+			}
+		}
+		if len(noAlt) > 0 {
+			warnings = append(warnings, fmt.Sprintf("%s: alt 텍스트가 없는 이미지 %d개 (%s)",
+				s.imageDir(), len(noAlt), strings.Join(noAlt, ", ")))
+		}
+	}
+	for _, dir := range nfcCheckDirs {
+		names, err := nonNFCNames(dir)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", dir, err))
+		}
+		for _, name := range names {
+			warnings = append(warnings, fmt.Sprintf("%s: 이름이 NFC가 아닙니다(macOS NFD). 같은 이름으로 다시 입력해 NFC로 바꾸세요", name))
+		}
+	}
+	return warnings
+}
+
+func logWarnings(warnings []string) {
+	for _, w := range warnings {
+		log.Printf("store: warning: %s", w)
+	}
+}